@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/melkeydev/mcp-database/audit"
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// detailWidth caps the SQL or arguments shown per record in table output.
+const detailWidth = 120
+
+// runAudit implements "mcp-database audit": it prints the audit records
+// matching the flags, oldest first.
+func runAudit(args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	configPath := flags.String("config", "config.yaml", "path to config file")
+	source := flags.String("source", "", "sink to read: sqlite or file (default: sqlite if configured, else file)")
+	since := flags.String("since", "", "only records at or after this time: a duration such as 24h, or RFC 3339")
+	until := flags.String("until", "", "only records before this time: a duration such as 1h, or RFC 3339")
+	tool := flags.String("tool", "", "only calls to this tool")
+	principal := flags.String("principal", "", "only calls by this principal, e.g. api_key:reporting-agent")
+	database := flags.String("database", "", "only calls against this database")
+	blocked := flags.Bool("blocked", false, "only calls rejected by policy")
+	failed := flags.Bool("errors", false, "only calls that returned an error")
+	limit := flags.Int("limit", 50, "show at most this many of the most recent records; 0 shows all")
+	asJSON := flags.Bool("json", false, "print records as JSON lines")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	filter := audit.Filter{
+		Tool:      *tool,
+		Principal: *principal,
+		Database:  *database,
+		Blocked:   *blocked,
+		Failed:    *failed,
+		Limit:     *limit,
+	}
+	if filter.Since, err = parseTime(*since); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if filter.Until, err = parseTime(*until); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+
+	records, err := audit.Query(context.Background(), cfg.Audit, *source, filter)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tPRINCIPAL\tTOOL\tDATABASE\tMS\tROWS\tSTATUS\tDETAIL")
+	for _, record := range records {
+		rows := "-"
+		if record.RowCount != nil {
+			rows = fmt.Sprint(*record.RowCount)
+		}
+		principal := record.Principal
+		if principal == "" {
+			principal = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\t%s\t%s\t%s\n",
+			record.Time.Local().Format(time.DateTime), principal, record.Tool, record.Database,
+			record.DurationMS, rows, status(record), detail(record))
+	}
+	return w.Flush()
+}
+
+// parseTime accepts a duration before now or an RFC 3339 time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func status(record audit.Record) string {
+	switch {
+	case record.Blocked:
+		return "blocked"
+	case record.Error != "":
+		return "error"
+	default:
+		return "ok"
+	}
+}
+
+// detail is the error of a failed call, else its SQL or arguments.
+func detail(record audit.Record) string {
+	var text string
+	switch {
+	case record.Error != "":
+		text = record.Error
+	case record.SQL != "":
+		text = record.SQL
+	case len(record.Arguments) > 0:
+		encoded, _ := json.Marshal(record.Arguments)
+		text = string(encoded)
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > detailWidth {
+		text = string(runes[:detailWidth-3]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,217 @@
+// Package audit records every tool call made through the server: who made
+// it, what it asked for, the SQL it ran and how it ended.
+//
+// Records are written by the Logger's tool middleware once the handler
+// returns. Handlers add what only they know, such as the database used, the
+// normalized SQL or the number of rows returned, through the Set functions.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	goMCP "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/auth"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/guard"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+const (
+	SinkFile   = "file"
+	SinkSQLite = "sqlite"
+	SinkSlog   = "slog"
+)
+
+// Record describes one tool call.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Principal is the authenticated caller; empty over stdio
+	Principal string         `json:"principal,omitempty"`
+	Tool      string         `json:"tool"`
+	Database  string         `json:"database,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// SQL is the normalized statement the call ran, if it ran one
+	SQL        string  `json:"sql,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+	// RowCount is the number of rows returned, for calls that return rows
+	RowCount *int   `json:"row_count,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Blocked is set when the read-only guard or the access rules rejected
+	// the call
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	Write(record Record) error
+	Close() error
+}
+
+// Logger writes each record to every configured sink. A nil Logger records
+// nothing.
+type Logger struct {
+	sinks []Sink
+}
+
+// New opens the sinks in cfg. It returns nil when none are configured.
+func New(cfg config.AuditConfig) (*Logger, error) {
+	if len(cfg.Sinks) == 0 {
+		return nil, nil
+	}
+
+	l := &Logger{}
+	for i, sinkCfg := range cfg.Sinks {
+		sink, err := openSink(sinkCfg)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("audit sink %d: %w", i+1, err)
+		}
+		l.sinks = append(l.sinks, sink)
+	}
+	return l, nil
+}
+
+func openSink(cfg config.AuditSinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case SinkFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file sink needs a path")
+		}
+		return NewFileSink(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+	case SinkSQLite:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("sqlite sink needs a path")
+		}
+		return NewSQLiteSink(cfg.Path)
+	case SinkSlog:
+		return NewSlogSink(slog.Default()), nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q (expected %s, %s or %s)", cfg.Type, SinkFile, SinkSQLite, SinkSlog)
+	}
+}
+
+// Log writes record to every sink. Failures are logged rather than
+// returned so a broken sink does not fail the tool call.
+func (l *Logger) Log(record Record) {
+	if l == nil {
+		return
+	}
+	for _, sink := range l.sinks {
+		if err := sink.Write(record); err != nil {
+			slog.Warn("failed to write audit record", "tool", record.Tool, "error", err)
+		}
+	}
+}
+
+// Close closes every sink.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Middleware records each tool call once its handler returns. Install it
+// with server.WithToolHandlerMiddleware.
+func (l *Logger) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if l == nil {
+		return next
+	}
+	return func(ctx context.Context, request goMCP.CallToolRequest) (*goMCP.CallToolResult, error) {
+		c := &call{}
+		start := time.Now()
+		result, err := next(context.WithValue(ctx, callKey{}, c), request)
+
+		record := Record{
+			Time:       start.UTC(),
+			Tool:       request.Params.Name,
+			Arguments:  request.GetArguments(),
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if p, ok := auth.FromContext(ctx); ok {
+			record.Principal = p.String()
+		}
+		c.fill(&record)
+
+		switch {
+		case err != nil:
+			record.Error = err.Error()
+		case result != nil && result.IsError:
+			record.Error = resultText(result)
+		}
+
+		l.Log(record)
+		return result, err
+	}
+}
+
+// resultText returns the text of an error result.
+func resultText(result *goMCP.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(goMCP.TextContent); ok {
+			return text.Text
+		}
+	}
+	return "error"
+}
+
+type callKey struct{}
+
+// call collects what the handler reports about a running tool call.
+type call struct {
+	mu       sync.Mutex
+	database string
+	sql      string
+	rowCount *int
+	blocked  bool
+}
+
+func (c *call) fill(record *Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	record.Database = c.database
+	record.SQL = c.sql
+	record.RowCount = c.rowCount
+	record.Blocked = c.blocked
+}
+
+// update runs fn on the call in ctx, if the call is being audited.
+func update(ctx context.Context, fn func(c *call)) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c)
+}
+
+// SetDatabase records the database the tool call uses.
+func SetDatabase(ctx context.Context, name string) {
+	update(ctx, func(c *call) { c.database = name })
+}
+
+// SetSQL records the statement the tool call runs, normalized for dialect.
+func SetSQL(ctx context.Context, dialect types.Dialect, query string) {
+	update(ctx, func(c *call) { c.sql = guard.Normalize(dialect, query) })
+}
+
+// SetRowCount records the number of rows the tool call returns.
+func SetRowCount(ctx context.Context, n int) {
+	update(ctx, func(c *call) { c.rowCount = &n })
+}
+
+// SetBlocked marks the tool call as rejected by policy.
+func SetBlocked(ctx context.Context) {
+	update(ctx, func(c *call) { c.blocked = true })
+}
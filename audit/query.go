@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// Filter selects audit records. Zero fields match everything.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Tool      string
+	Principal string
+	Database  string
+	// Blocked keeps only calls rejected by policy
+	Blocked bool
+	// Failed keeps only calls that returned an error
+	Failed bool
+	// Limit keeps the most recent records; 0 keeps all
+	Limit int
+}
+
+func (f Filter) matches(r Record) bool {
+	switch {
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	case f.Tool != "" && r.Tool != f.Tool:
+		return false
+	case f.Principal != "" && r.Principal != f.Principal:
+		return false
+	case f.Database != "" && r.Database != f.Database:
+		return false
+	case f.Blocked && !r.Blocked:
+		return false
+	case f.Failed && r.Error == "":
+		return false
+	}
+	return true
+}
+
+// Query reads the records matching filter, oldest first, from a sink in
+// cfg. source names the sink type to read; empty picks the sqlite sink if
+// there is one, else the file sink.
+func Query(ctx context.Context, cfg config.AuditConfig, source string, filter Filter) ([]Record, error) {
+	sink, err := readableSink(cfg, source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch sink.Type {
+	case SinkSQLite:
+		return readSQLite(ctx, sink.Path, filter)
+	default:
+		return readFile(sink.Path, sink.MaxBackups, filter)
+	}
+}
+
+func readableSink(cfg config.AuditConfig, source string) (config.AuditSinkConfig, error) {
+	candidates := []string{SinkSQLite, SinkFile}
+	switch source {
+	case "":
+	case SinkSQLite, SinkFile:
+		candidates = []string{source}
+	default:
+		return config.AuditSinkConfig{}, fmt.Errorf("cannot read audit records from %q (expected %s or %s)", source, SinkFile, SinkSQLite)
+	}
+
+	for _, candidate := range candidates {
+		for _, sink := range cfg.Sinks {
+			if sink.Type == candidate {
+				return sink, nil
+			}
+		}
+	}
+	if source != "" {
+		return config.AuditSinkConfig{}, fmt.Errorf("no %s audit sink is configured", source)
+	}
+	return config.AuditSinkConfig{}, fmt.Errorf("no file or sqlite audit sink is configured")
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogSink writes records to a structured logger. Blocked and failed calls
+// are logged as warnings.
+type SlogSink struct {
+	logger *slog.Logger
+}
+
+func NewSlogSink(logger *slog.Logger) *SlogSink {
+	return &SlogSink{logger: logger}
+}
+
+func (s *SlogSink) Write(record Record) error {
+	attrs := []slog.Attr{
+		slog.Time("time", record.Time),
+		slog.String("tool", record.Tool),
+		slog.Float64("duration_ms", record.DurationMS),
+	}
+	if record.Principal != "" {
+		attrs = append(attrs, slog.String("principal", record.Principal))
+	}
+	if record.Database != "" {
+		attrs = append(attrs, slog.String("database", record.Database))
+	}
+	if len(record.Arguments) > 0 {
+		attrs = append(attrs, slog.Any("arguments", record.Arguments))
+	}
+	if record.SQL != "" {
+		attrs = append(attrs, slog.String("sql", record.SQL))
+	}
+	if record.RowCount != nil {
+		attrs = append(attrs, slog.Int("row_count", *record.RowCount))
+	}
+	if record.Error != "" {
+		attrs = append(attrs, slog.String("error", record.Error))
+	}
+	if record.Blocked {
+		attrs = append(attrs, slog.Bool("blocked", true))
+	}
+
+	level := slog.LevelInfo
+	if record.Blocked || record.Error != "" {
+		level = slog.LevelWarn
+	}
+	s.logger.LogAttrs(context.Background(), level, "audit", attrs...)
+	return nil
+}
+
+func (s *SlogSink) Close() error {
+	return nil
+}
@@ -0,0 +1,183 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// timeLayout stores times in UTC with a fixed width so they sort as text.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+const createAuditTable = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id          INTEGER PRIMARY KEY,
+	time        TEXT NOT NULL,
+	principal   TEXT NOT NULL DEFAULT '',
+	tool        TEXT NOT NULL,
+	database    TEXT NOT NULL DEFAULT '',
+	arguments   TEXT,
+	sql         TEXT NOT NULL DEFAULT '',
+	duration_ms REAL NOT NULL,
+	row_count   INTEGER,
+	error       TEXT NOT NULL DEFAULT '',
+	blocked     INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS audit_log_time ON audit_log (time);
+`
+
+// SQLiteSink stores records in the audit_log table of a SQLite database.
+type SQLiteSink struct {
+	db *sqlx.DB
+}
+
+// NewSQLiteSink opens the database at path, creating it and the audit_log
+// table if needed.
+func NewSQLiteSink(path string) (*SQLiteSink, error) {
+	db, err := openAuditDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`PRAGMA journal_mode = WAL`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open audit database: %w", err)
+	}
+	if _, err := db.Exec(createAuditTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit table: %w", err)
+	}
+	return &SQLiteSink{db: db}, nil
+}
+
+func openAuditDB(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit database: %w", err)
+	}
+	// SQLite allows one writer at a time
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+func (s *SQLiteSink) Write(record Record) error {
+	var arguments sql.NullString
+	if len(record.Arguments) > 0 {
+		encoded, err := json.Marshal(record.Arguments)
+		if err != nil {
+			return err
+		}
+		arguments = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO audit_log (time, principal, tool, database, arguments, sql, duration_ms, row_count, error, blocked)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.Time.UTC().Format(timeLayout), record.Principal, record.Tool, record.Database, arguments,
+		record.SQL, record.DurationMS, record.RowCount, record.Error, record.Blocked,
+	)
+	return err
+}
+
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
+
+type auditRow struct {
+	Time       string         `db:"time"`
+	Principal  string         `db:"principal"`
+	Tool       string         `db:"tool"`
+	Database   string         `db:"database"`
+	Arguments  sql.NullString `db:"arguments"`
+	SQL        string         `db:"sql"`
+	DurationMS float64        `db:"duration_ms"`
+	RowCount   sql.NullInt64  `db:"row_count"`
+	Error      string         `db:"error"`
+	Blocked    bool           `db:"blocked"`
+}
+
+// readSQLite reads the matching records, oldest first.
+func readSQLite(ctx context.Context, path string, filter Filter) ([]Record, error) {
+	db, err := openAuditDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var where []string
+	var args []any
+	add := func(condition string, arg any) {
+		where = append(where, condition)
+		args = append(args, arg)
+	}
+	if !filter.Since.IsZero() {
+		add("time >= ?", filter.Since.UTC().Format(timeLayout))
+	}
+	if !filter.Until.IsZero() {
+		add("time < ?", filter.Until.UTC().Format(timeLayout))
+	}
+	if filter.Tool != "" {
+		add("tool = ?", filter.Tool)
+	}
+	if filter.Principal != "" {
+		add("principal = ?", filter.Principal)
+	}
+	if filter.Database != "" {
+		add("database = ?", filter.Database)
+	}
+	if filter.Blocked {
+		where = append(where, "blocked")
+	}
+	if filter.Failed {
+		where = append(where, "error <> ''")
+	}
+
+	query := `SELECT time, principal, tool, database, arguments, sql, duration_ms, row_count, error, blocked FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY time DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	var rows []auditRow
+	if err := db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		record := Record{
+			Principal:  row.Principal,
+			Tool:       row.Tool,
+			Database:   row.Database,
+			SQL:        row.SQL,
+			DurationMS: row.DurationMS,
+			Error:      row.Error,
+			Blocked:    row.Blocked,
+		}
+		record.Time, err = time.Parse(timeLayout, row.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid audit record time %q: %w", row.Time, err)
+		}
+		if row.Arguments.Valid {
+			if err := json.Unmarshal([]byte(row.Arguments.String), &record.Arguments); err != nil {
+				return nil, fmt.Errorf("invalid audit record arguments: %w", err)
+			}
+		}
+		if row.RowCount.Valid {
+			n := int(row.RowCount.Int64)
+			record.RowCount = &n
+		}
+		// Newest first from the query, oldest first in the result
+		records[len(rows)-1-i] = record
+	}
+	return records, nil
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// APIKeyAuthenticator accepts static keys sent either as a bearer token or
+// in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	name string
+	hash [sha256.Size]byte
+}
+
+// NewAPIKeyAuthenticator loads the configured keys, reading KeyEnv entries
+// from the environment.
+func NewAPIKeyAuthenticator(keys []config.APIKeyConfig) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+	for i, k := range keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("auth.api_keys[%d]: name is required", i)
+		}
+
+		value := k.Key
+		if k.KeyEnv != "" {
+			value = os.Getenv(k.KeyEnv)
+			if value == "" {
+				return nil, fmt.Errorf("auth.api_keys[%d] (%s): environment variable %s is not set", i, k.Name, k.KeyEnv)
+			}
+		}
+		if value == "" {
+			return nil, fmt.Errorf("auth.api_keys[%d] (%s): key or key_env is required", i, k.Name)
+		}
+
+		a.keys = append(a.keys, apiKey{name: k.Name, hash: sha256.Sum256([]byte(value))})
+	}
+	return a, nil
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	presented := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if presented == "" {
+		presented = bearerToken(r)
+	}
+	if presented == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// Hashing gives equal-length inputs so the comparison is constant time
+	hash := sha256.Sum256([]byte(presented))
+	var match *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], a.keys[i].hash[:]) == 1 {
+			match = &a.keys[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("invalid api key")
+	}
+
+	return &Principal{Subject: match.name, Method: MethodAPIKey}, nil
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	t.Setenv("TEST_CI_KEY", "ci-secret")
+	a, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{
+		{Name: "laptop", Key: "laptop-secret"},
+		{Name: "ci", KeyEnv: "TEST_CI_KEY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		subject string
+		noCreds bool
+	}{
+		{"x-api-key header", map[string]string{"X-API-Key": "laptop-secret"}, "laptop", false},
+		{"bearer token", map[string]string{"Authorization": "Bearer laptop-secret"}, "laptop", false},
+		{"lower-case bearer", map[string]string{"Authorization": "bearer ci-secret"}, "ci", false},
+		{"key from environment", map[string]string{"X-API-Key": "ci-secret"}, "ci", false},
+		{"x-api-key wins", map[string]string{"X-API-Key": "ci-secret", "Authorization": "Bearer laptop-secret"}, "ci", false},
+		{"wrong key", map[string]string{"X-API-Key": "guess"}, "", false},
+		{"wrong bearer", map[string]string{"Authorization": "Bearer guess"}, "", false},
+		{"key prefix", map[string]string{"X-API-Key": "laptop-secre"}, "", false},
+		{"basic auth", map[string]string{"Authorization": "Basic laptop-secret"}, "", true},
+		{"no credentials", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			p, err := a.Authenticate(r)
+			switch {
+			case tt.noCreds:
+				if err != ErrNoCredentials {
+					t.Fatalf("Authenticate() error = %v, want ErrNoCredentials", err)
+				}
+			case tt.subject == "":
+				if err == nil || err == ErrNoCredentials {
+					t.Fatalf("Authenticate() = %v, %v, want an invalid key error", p, err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("Authenticate() error = %v", err)
+				}
+				if p.Subject != tt.subject || p.Method != MethodAPIKey {
+					t.Errorf("Authenticate() = %v, want api_key:%s", p, tt.subject)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAPIKeyAuthenticatorErrors(t *testing.T) {
+	tests := map[string]config.APIKeyConfig{
+		"missing name":  {Key: "secret"},
+		"missing key":   {Name: "laptop"},
+		"unset key env": {Name: "ci", KeyEnv: "TEST_UNSET_KEY"},
+	}
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{key}); err == nil {
+				t.Error("NewAPIKeyAuthenticator() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Package auth authenticates requests made over the HTTP transports and
+// carries the resulting principal through the request context.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries
+// no credentials it understands.
+var ErrNoCredentials = errors.New("no credentials")
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller: the API key name or the JWT subject
+	Subject string `json:"subject"`
+	// Method is how the caller authenticated, MethodAPIKey or MethodJWT
+	Method string `json:"method"`
+	// Claims holds the verified JWT claims; nil for API keys
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+// Authenticator verifies the credentials on a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any. Calls made over
+// stdio have no principal.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// New builds the authenticators enabled in cfg. It returns nil when no
+// authentication is configured.
+func New(cfg config.AuthConfig) (Authenticator, error) {
+	var chain Chain
+
+	if len(cfg.APIKeys) > 0 {
+		a, err := NewAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, a)
+	}
+
+	if cfg.JWT.JWKSFile != "" {
+		a, err := NewJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, a)
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// Chain tries each authenticator in turn and accepts the first success.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	var errs []error
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil, ErrNoCredentials
+	}
+	return nil, errors.Join(errs...)
+}
+
+// Middleware rejects requests that a fails to authenticate and stores the
+// principal of accepted requests in the request context.
+func Middleware(a Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(r)
+		if err != nil {
+			slog.Warn("authentication failed", "remote_addr", r.RemoteAddr, "path", r.URL.Path, "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-database"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func (p *Principal) String() string {
+	return fmt.Sprintf("%s:%s", p.Method, p.Subject)
+}
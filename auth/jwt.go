@@ -0,0 +1,356 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// JWTAuthenticator verifies bearer JWTs against the keys in a local JWKS
+// file. Only asymmetric algorithms (RS*, PS*, ES*) are accepted.
+type JWTAuthenticator struct {
+	keys         []jwk
+	issuer       string
+	audience     string
+	subjectClaim string
+	leeway       time.Duration
+	now          func() time.Time
+}
+
+type jwk struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// NewJWTAuthenticator loads the JWKS file named in cfg.
+func NewJWTAuthenticator(cfg config.JWTConfig) (*JWTAuthenticator, error) {
+	data, err := os.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse jwks file %s: %w", cfg.JWKSFile, err)
+	}
+
+	subjectClaim := cfg.SubjectClaim
+	if subjectClaim == "" {
+		subjectClaim = "sub"
+	}
+
+	return &JWTAuthenticator{
+		keys:         keys,
+		issuer:       cfg.Issuer,
+		audience:     cfg.Audience,
+		subjectClaim: subjectClaim,
+		leeway:       cfg.Leeway,
+		now:          time.Now,
+	}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := bearerToken(r)
+	// Anything that is not header.payload.signature is left to other
+	// authenticators, e.g. an opaque API key
+	if strings.Count(token, ".") != 2 {
+		return nil, ErrNoCredentials
+	}
+
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt: %w", err)
+	}
+
+	subject, _ := claims[a.subjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("invalid jwt: missing %s claim", a.subjectClaim)
+	}
+
+	return &Principal{Subject: subject, Method: MethodJWT, Claims: claims}, nil
+}
+
+func (a *JWTAuthenticator) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+
+	if err := a.verifySignature(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("claims: %w", err)
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) verifySignature(alg, kid string, signed, signature []byte) error {
+	hash, ok := algHash(alg)
+	if !ok {
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	tried := false
+	for _, k := range a.keys {
+		if kid != "" && k.kid != kid {
+			continue
+		}
+		if k.alg != "" && k.alg != alg {
+			continue
+		}
+
+		var err error
+		switch pub := k.key.(type) {
+		case *rsa.PublicKey:
+			switch alg[:2] {
+			case "RS":
+				err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+			case "PS":
+				err = rsa.VerifyPSS(pub, hash, digest, signature, nil)
+			default:
+				continue
+			}
+		case *ecdsa.PublicKey:
+			if alg[:2] != "ES" || !ecdsaMatches(pub, alg) {
+				continue
+			}
+			err = verifyECDSA(pub, digest, signature)
+		default:
+			continue
+		}
+
+		tried = true
+		if err == nil {
+			return nil
+		}
+	}
+
+	if !tried {
+		return fmt.Errorf("no key in jwks matches kid %q and alg %s", kid, alg)
+	}
+	return errors.New("signature verification failed")
+}
+
+func (a *JWTAuthenticator) validateClaims(claims map[string]any) error {
+	now := a.now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if now.After(exp.Add(a.leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(a.leeway).Before(nbf) {
+		return errors.New("token not yet valid")
+	}
+
+	if a.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+
+	if a.audience != "" && !audienceContains(claims["aud"], a.audience) {
+		return fmt.Errorf("token is not intended for audience %q", a.audience)
+	}
+	return nil
+}
+
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	v, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// audienceContains handles aud given as a single string or an array.
+func audienceContains(aud any, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func algHash(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[:2] {
+	case "RS", "PS", "ES":
+	default:
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// ecdsaMatches checks that the curve is the one the alg is defined for.
+func ecdsaMatches(pub *ecdsa.PublicKey, alg string) bool {
+	switch alg {
+	case "ES256":
+		return pub.Curve == elliptic.P256()
+	case "ES384":
+		return pub.Curve == elliptic.P384()
+	case "ES512":
+		return pub.Curve == elliptic.P521()
+	}
+	return false
+}
+
+// verifyECDSA checks a JWS ECDSA signature, which is r and s concatenated
+// rather than ASN.1 encoded.
+func verifyECDSA(pub *ecdsa.PublicKey, digest, signature []byte) error {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	if len(signature) != 2*size {
+		return errors.New("malformed ecdsa signature")
+	}
+	r := new(big.Int).SetBytes(signature[:size])
+	s := new(big.Int).SetBytes(signature[size:])
+	if !ecdsa.Verify(pub, digest, r, s) {
+		return errors.New("ecdsa verification failed")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// parseJWKS reads the RSA and EC signing keys from a JWK set. Keys marked
+// for encryption and key types other than RSA and EC are skipped.
+func parseJWKS(data []byte) ([]jwk, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []jwk
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var pub crypto.PublicKey
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: n: %w", i, err)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: e: %w", i, err)
+			}
+			if !e.IsInt64() || e.Int64() > 1<<31-1 {
+				return nil, fmt.Errorf("key %d: exponent too large", i)
+			}
+			pub = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("key %d: unsupported curve %q", i, k.Crv)
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: x: %w", i, err)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, fmt.Errorf("key %d: y: %w", i, err)
+			}
+			if !curve.IsOnCurve(x, y) {
+				return nil, fmt.Errorf("key %d: point is not on curve %s", i, k.Crv)
+			}
+			pub = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		default:
+			continue
+		}
+
+		keys = append(keys, jwk{kid: k.Kid, alg: k.Alg, key: pub})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+	return keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
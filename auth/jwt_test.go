@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testKeys holds the private halves of the keys in the test JWKS.
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+// newTestAuthenticator writes a JWKS with an RSA key usable for any RS*
+// or PS* alg (kid "rsa"), the same RSA key pinned to RS256 (kid
+// "rsa-rs256") and a P-256 key (kid "ec").
+func newTestAuthenticator(t *testing.T) (*JWTAuthenticator, testKeys) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	rsaJWK := func(kid, alg string) map[string]string {
+		return map[string]string{"kty": "RSA", "kid": kid, "alg": alg, "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))}
+	}
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		rsaJWK("rsa", ""),
+		rsaJWK("rsa-rs256", "RS256"),
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewJWTAuthenticator(config.JWTConfig{
+		JWKSFile: path,
+		Issuer:   "https://issuer.example",
+		Audience: "mcp-database",
+		Leeway:   30 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return testNow }
+	return a, testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+// sign builds a JWT. The RSA key signs RS* and PS* tokens, the EC key ES*
+// ones; any other alg gets an empty signature.
+func sign(t *testing.T, keys testKeys, header, claims map[string]any) string {
+	t.Helper()
+
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch alg, _ := header["alg"].(string); alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, keys.rsa, crypto.SHA256, digest[:])
+	case "PS256":
+		signature, err = rsa.SignPSS(rand.Reader, keys.rsa, crypto.SHA256, digest[:], nil)
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, keys.ec, digest[:])
+		if err == nil {
+			signature = make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "alice",
+		"iss": "https://issuer.example",
+		"aud": "mcp-database",
+		"exp": float64(testNow.Add(time.Hour).Unix()),
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	a, keys := newTestAuthenticator(t)
+
+	tests := []struct {
+		name   string
+		header map[string]any
+		// claims changes validClaims; nil values delete the claim
+		claims map[string]any
+		valid  bool
+	}{
+		{"RS256", map[string]any{"alg": "RS256", "kid": "rsa"}, nil, true},
+		{"PS256", map[string]any{"alg": "PS256", "kid": "rsa"}, nil, true},
+		{"ES256", map[string]any{"alg": "ES256", "kid": "ec"}, nil, true},
+		{"no kid", map[string]any{"alg": "ES256"}, nil, true},
+		{"key pinned to alg", map[string]any{"alg": "RS256", "kid": "rsa-rs256"}, nil, true},
+		{"audience in array", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"aud": []any{"other", "mcp-database"}}, true},
+		{"alg none", map[string]any{"alg": "none"}, nil, false},
+		{"HS256", map[string]any{"alg": "HS256", "kid": "rsa"}, nil, false},
+		{"wrong kid", map[string]any{"alg": "RS256", "kid": "unknown"}, nil, false},
+		{"kid of another key type", map[string]any{"alg": "ES256", "kid": "rsa"}, nil, false},
+		{"key alg mismatch", map[string]any{"alg": "PS256", "kid": "rsa-rs256"}, nil, false},
+		{"expired within leeway", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"exp": float64(testNow.Add(-10 * time.Second).Unix())}, true},
+		{"expired", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"exp": float64(testNow.Add(-time.Minute).Unix())}, false},
+		{"missing exp", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"exp": nil}, false},
+		{"not yet valid within leeway", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"nbf": float64(testNow.Add(10 * time.Second).Unix())}, true},
+		{"not yet valid", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"nbf": float64(testNow.Add(time.Minute).Unix())}, false},
+		{"wrong issuer", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"iss": "https://other.example"}, false},
+		{"wrong audience", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"aud": "other"}, false},
+		{"audience array without ours", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"aud": []any{"other"}}, false},
+		{"missing subject", map[string]any{"alg": "RS256", "kid": "rsa"}, map[string]any{"sub": nil}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			for k, v := range tt.claims {
+				if v == nil {
+					delete(claims, k)
+				} else {
+					claims[k] = v
+				}
+			}
+
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			r.Header.Set("Authorization", "Bearer "+sign(t, keys, tt.header, claims))
+
+			p, err := a.Authenticate(r)
+			if !tt.valid {
+				if err == nil {
+					t.Fatalf("Authenticate() = %v, want an error", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if p.Subject != "alice" || p.Method != MethodJWT {
+				t.Errorf("Authenticate() = %v, want jwt:alice", p)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticateTampered(t *testing.T) {
+	a, keys := newTestAuthenticator(t)
+	token := sign(t, keys, map[string]any{"alg": "ES256", "kid": "ec"}, validClaims())
+	parts := strings.Split(token, ".")
+
+	other := validClaims()
+	other["sub"] = "mallory"
+	forged := sign(t, keys, map[string]any{"alg": "ES256", "kid": "ec"}, other)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"claims swapped":         parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2],
+		"short es signature":     parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature[:63]),
+		"long es signature":      parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(append(signature, 0)),
+		"asn.1 style signature":  parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(append([]byte{0x30, 0x44}, signature...)),
+		"signature not base64":   parts[0] + "." + parts[1] + ".!!",
+		"header not json base64": "e30x." + parts[1] + "." + parts[2],
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/mcp", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			if p, err := a.Authenticate(r); err == nil {
+				t.Fatalf("Authenticate() = %v, want an error", p)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticateNoToken(t *testing.T) {
+	a, _ := newTestAuthenticator(t)
+
+	for _, header := range []string{"", "Bearer opaque-api-key", "Basic dXNlcjpwYXNz"} {
+		r := httptest.NewRequest("POST", "/mcp", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		if _, err := a.Authenticate(r); err != ErrNoCredentials {
+			t.Errorf("Authenticate() with %q error = %v, want ErrNoCredentials", header, err)
+		}
+	}
+}
+
+func TestVerifyECDSALength(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte("payload"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	if err := verifyECDSA(&key.PublicKey, digest[:], signature); err != nil {
+		t.Fatalf("verifyECDSA() error = %v", err)
+	}
+	for _, n := range []int{0, 32, 63, 65, 96} {
+		padded := make([]byte, n)
+		copy(padded, signature)
+		if err := verifyECDSA(&key.PublicKey, digest[:], padded); err == nil || !strings.Contains(err.Error(), "malformed") {
+			t.Errorf("verifyECDSA() with %d bytes error = %v, want malformed", n, err)
+		}
+	}
+}
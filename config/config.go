@@ -3,18 +3,217 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
+	// Database is the single-connection form kept for existing configs. It
+	// is folded into Databases under the name "default" on load.
+	Database        DatabaseConfig            `yaml:"database,omitempty"`
+	Databases       map[string]DatabaseConfig `yaml:"databases,omitempty"`
+	DefaultDatabase string                    `yaml:"default_database,omitempty"`
+	Limits          LimitsConfig              `yaml:"limits"`
+	Server          ServerConfig              `yaml:"server"`
+	Auth            AuthConfig                `yaml:"auth"`
+	Redaction       RedactionConfig           `yaml:"redaction"`
+	Audit           AuditConfig               `yaml:"audit"`
+	History         HistoryConfig             `yaml:"history"`
 }
 
 type DatabaseConfig struct {
-	DBType           string `yaml:"type"`
-	ConnectionString string `yaml:"connection_string,omitempty"`
-	File             string `yaml:"file,omitempty"`
+	DBType           string       `yaml:"type"`
+	ConnectionString string       `yaml:"connection_string,omitempty"`
+	File             string       `yaml:"file,omitempty"`
+	Schemas          SchemaConfig `yaml:"schemas,omitempty"`
+	// Tables and Columns hide objects from every tool; see AccessConfig
+	Tables  AccessConfig `yaml:"tables,omitempty"`
+	Columns AccessConfig `yaml:"columns,omitempty"`
+	// SchemaCacheTTL is how long table and column lists are cached. Unset
+	// means 5m; a negative value disables the cache.
+	SchemaCacheTTL time.Duration `yaml:"schema_cache_ttl,omitempty"`
+}
+
+// SchemaConfig limits which schemas are visible to the tools. For MySQL a
+// schema is a database and for SQLite an attached database. System schemas
+// are hidden unless listed in Include; without an Include list MySQL only
+// shows the database from the connection string.
+type SchemaConfig struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// AccessConfig lists the tables or columns the tools may see. Table
+// entries are table or schema.table patterns, column entries column,
+// table.column or schema.table.column patterns, all with * and ?
+// wildcards. Exclusions win; a column include list only restricts the
+// tables its entries name.
+type AccessConfig struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// LimitsConfig caps how much data a single tool call may pull from the
+// database. Zero values are replaced by defaults when the config is loaded.
+type LimitsConfig struct {
+	SampleDefaultRows int `yaml:"sample_default_rows"`
+	SampleMaxRows     int `yaml:"sample_max_rows"`
+	// MaxRows caps the rows query_database returns; callers may ask for fewer
+	MaxRows int `yaml:"max_rows"`
+	// MaxResultBytes caps the JSON encoded size of query_database's rows
+	MaxResultBytes int `yaml:"max_result_bytes"`
+	// QueryTimeout bounds how long a query_database statement may run;
+	// callers may ask for less
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// MaxPlanCost and MaxPlanRows make query_database explain each query
+	// first and refuse it when the planner's cost or row estimate is
+	// higher. Zero disables the check; SQLite plans have no estimates
+	MaxPlanCost float64 `yaml:"max_plan_cost,omitempty"`
+	MaxPlanRows float64 `yaml:"max_plan_rows,omitempty"`
+}
+
+func (l *LimitsConfig) applyDefaults() {
+	if l.SampleDefaultRows <= 0 {
+		l.SampleDefaultRows = 10
+	}
+	if l.SampleMaxRows <= 0 {
+		l.SampleMaxRows = 100
+	}
+	if l.SampleDefaultRows > l.SampleMaxRows {
+		l.SampleDefaultRows = l.SampleMaxRows
+	}
+	if l.MaxRows <= 0 {
+		l.MaxRows = 1000
+	}
+	if l.MaxResultBytes <= 0 {
+		l.MaxResultBytes = 1 << 20
+	}
+	if l.QueryTimeout <= 0 {
+		l.QueryTimeout = 30 * time.Second
+	}
+}
+
+// ServerConfig selects how MCP clients reach the server. Address, BasePath
+// and ShutdownTimeout only apply to the HTTP transports.
+type ServerConfig struct {
+	// Transport is one of stdio, sse or streamable-http
+	Transport       string        `yaml:"transport"`
+	Address         string        `yaml:"address"`
+	BasePath        string        `yaml:"base_path"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// PageSize is the number of entries per page of resources/list
+	PageSize int `yaml:"page_size"`
+}
+
+// AuthConfig protects the HTTP transports. A request is accepted when any
+// configured method authenticates it; with nothing configured every request
+// is accepted. Stdio is never authenticated.
+type AuthConfig struct {
+	APIKeys []APIKeyConfig `yaml:"api_keys,omitempty"`
+	JWT     JWTConfig      `yaml:"jwt,omitempty"`
+}
+
+// APIKeyConfig is a static key. The key is given inline or read from the
+// environment variable named by KeyEnv; Name identifies the caller.
+type APIKeyConfig struct {
+	Name   string `yaml:"name"`
+	Key    string `yaml:"key,omitempty"`
+	KeyEnv string `yaml:"key_env,omitempty"`
+}
+
+// JWTConfig verifies bearer JWTs against a local JWKS file. Issuer and
+// Audience are only checked when set.
+type JWTConfig struct {
+	JWKSFile     string        `yaml:"jwks_file,omitempty"`
+	Issuer       string        `yaml:"issuer,omitempty"`
+	Audience     string        `yaml:"audience,omitempty"`
+	SubjectClaim string        `yaml:"subject_claim,omitempty"`
+	Leeway       time.Duration `yaml:"leeway,omitempty"`
+}
+
+// RedactionConfig masks sensitive values in the rows returned by
+// query_database, sample_table and describe_table before they reach the
+// client.
+type RedactionConfig struct {
+	Rules []RedactionRule `yaml:"rules,omitempty"`
+	// HashKey keys the hash action so hashed values cannot be confirmed by
+	// hashing guesses. Given inline or read from HashKeyEnv.
+	HashKey    string `yaml:"hash_key,omitempty"`
+	HashKeyEnv string `yaml:"hash_key_env,omitempty"`
+}
+
+// RedactionRule applies Action to the columns matching Columns and to the
+// text matching Values. Columns entries are column, table.column or
+// schema.table.column patterns with * and ? wildcards. Values entries are
+// the built-in detectors email, credit_card and jwt, or regular expressions.
+type RedactionRule struct {
+	Columns []string `yaml:"columns,omitempty"`
+	Values  []string `yaml:"values,omitempty"`
+	// Action is hash, mask or drop
+	Action string `yaml:"action"`
+}
+
+// AuditConfig records every tool call to the configured sinks. Without
+// sinks nothing is recorded.
+type AuditConfig struct {
+	Sinks []AuditSinkConfig `yaml:"sinks,omitempty"`
+}
+
+// AuditSinkConfig is one destination for audit records. Type is file (JSON
+// lines), sqlite or slog; Path applies to file and sqlite. A file sink is
+// rotated once it grows past MaxSizeMB, keeping MaxBackups old files.
+type AuditSinkConfig struct {
+	Type       string `yaml:"type"`
+	Path       string `yaml:"path,omitempty"`
+	MaxSizeMB  int    `yaml:"max_size_mb,omitempty"`
+	MaxBackups int    `yaml:"max_backups,omitempty"`
+}
+
+func (a *AuditConfig) applyDefaults() {
+	for i := range a.Sinks {
+		sink := &a.Sinks[i]
+		if sink.Type == "file" && sink.MaxSizeMB <= 0 {
+			sink.MaxSizeMB = 100
+		}
+		if sink.Type == "file" && sink.MaxBackups <= 0 {
+			sink.MaxBackups = 5
+		}
+	}
+}
+
+// HistoryConfig bounds the query_database history kept for each session.
+// With File set the history is also stored in that SQLite database and
+// survives restarts.
+type HistoryConfig struct {
+	MaxEntries int    `yaml:"max_entries"`
+	File       string `yaml:"file,omitempty"`
+}
+
+func (h *HistoryConfig) applyDefaults() {
+	if h.MaxEntries <= 0 {
+		h.MaxEntries = 100
+	}
+}
+
+func (s *ServerConfig) applyDefaults() {
+	if s.Transport == "" {
+		s.Transport = "stdio"
+	}
+	if s.Address == "" {
+		s.Address = ":8080"
+	}
+	if s.BasePath == "" {
+		s.BasePath = "/mcp"
+	}
+	s.BasePath = "/" + strings.Trim(s.BasePath, "/")
+	if s.ShutdownTimeout <= 0 {
+		s.ShutdownTimeout = 10 * time.Second
+	}
+	if s.PageSize <= 0 {
+		s.PageSize = 100
+	}
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -33,9 +232,61 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	if err := config.normalizeDatabases(); err != nil {
+		return nil, err
+	}
+	config.Limits.applyDefaults()
+	config.Server.applyDefaults()
+	config.Audit.applyDefaults()
+	config.History.applyDefaults()
+
 	return &config, nil
 }
 
+// normalizeDatabases folds the legacy single database into the named map
+// and makes sure the default connection exists.
+func (c *Config) normalizeDatabases() error {
+	if c.Database.DBType != "" {
+		if _, exists := c.Databases["default"]; exists {
+			return fmt.Errorf("database and databases.default cannot both be set")
+		}
+		if c.Databases == nil {
+			c.Databases = make(map[string]DatabaseConfig)
+		}
+		c.Databases["default"] = c.Database
+		c.Database = DatabaseConfig{}
+	}
+
+	if len(c.Databases) == 0 {
+		return fmt.Errorf("no database configured")
+	}
+
+	if c.DefaultDatabase == "" {
+		if _, ok := c.Databases["default"]; ok {
+			c.DefaultDatabase = "default"
+		} else if len(c.Databases) == 1 {
+			for name := range c.Databases {
+				c.DefaultDatabase = name
+			}
+		} else {
+			return fmt.Errorf("default_database is required when more than one database is configured")
+		}
+	}
+
+	if _, ok := c.Databases[c.DefaultDatabase]; !ok {
+		return fmt.Errorf("default_database %q is not configured", c.DefaultDatabase)
+	}
+
+	for name, db := range c.Databases {
+		if db.SchemaCacheTTL == 0 {
+			db.SchemaCacheTTL = 5 * time.Minute
+			c.Databases[name] = db
+		}
+	}
+
+	return nil
+}
+
 func (d *DatabaseConfig) GetConnectionString() (string, error) {
 	switch d.DBType {
 	case "postgres", "mysql":
@@ -0,0 +1,347 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/guard"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// AccessRules hide tables and columns from every tool. Hidden tables are
+// left out of the catalog, so they cannot be listed, resolved or sampled;
+// hidden columns are left out of column lists, and queries naming either
+// are rejected. Exclusions win over inclusions. A column include list only
+// restricts the tables its patterns name.
+type AccessRules struct {
+	tables  patternFilter
+	columns patternFilter
+}
+
+// patternFilter holds include and exclude patterns split into their
+// schema, table and column parts; empty parts match anything.
+type patternFilter struct {
+	include []namePattern
+	exclude []namePattern
+}
+
+type namePattern struct {
+	schema, table, column string
+}
+
+// NewAccessRules parses table patterns (table or schema.table) and column
+// patterns (column, table.column or schema.table.column). Patterns may use
+// * and ? wildcards.
+func NewAccessRules(tableInclude, tableExclude, columnInclude, columnExclude []string) (AccessRules, error) {
+	var rules AccessRules
+	var err error
+
+	if rules.tables.include, err = parsePatterns(tableInclude, false); err != nil {
+		return AccessRules{}, err
+	}
+	if rules.tables.exclude, err = parsePatterns(tableExclude, false); err != nil {
+		return AccessRules{}, err
+	}
+	if rules.columns.include, err = parsePatterns(columnInclude, true); err != nil {
+		return AccessRules{}, err
+	}
+	if rules.columns.exclude, err = parsePatterns(columnExclude, true); err != nil {
+		return AccessRules{}, err
+	}
+	return rules, nil
+}
+
+func parsePatterns(raw []string, column bool) ([]namePattern, error) {
+	patterns := make([]namePattern, 0, len(raw))
+	for _, r := range raw {
+		parts := strings.Split(r, ".")
+		for _, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("invalid pattern %q", r)
+			}
+		}
+
+		var p namePattern
+		switch {
+		case !column && len(parts) == 1:
+			p.table = parts[0]
+		case !column && len(parts) == 2:
+			p.schema, p.table = parts[0], parts[1]
+		case column && len(parts) == 1:
+			p.column = parts[0]
+		case column && len(parts) == 2:
+			p.table, p.column = parts[0], parts[1]
+		case column && len(parts) == 3:
+			p.schema, p.table, p.column = parts[0], parts[1], parts[2]
+		case column:
+			return nil, fmt.Errorf("invalid column pattern %q: expected column, table.column or schema.table.column", r)
+		default:
+			return nil, fmt.Errorf("invalid table pattern %q: expected table or schema.table", r)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+func (p namePattern) matchesTable(ref TableRef) bool {
+	if p.table != "" && !globMatch(p.table, ref.Name) {
+		return false
+	}
+	return p.schema == "" || globMatch(p.schema, ref.Schema)
+}
+
+func (p namePattern) matchesColumn(ref TableRef, column string) bool {
+	return p.matchesTable(ref) && globMatch(p.column, column)
+}
+
+// IsZero reports whether no rules are configured.
+func (a AccessRules) IsZero() bool {
+	return len(a.tables.include) == 0 && len(a.tables.exclude) == 0 &&
+		len(a.columns.include) == 0 && len(a.columns.exclude) == 0
+}
+
+// TableVisible reports whether ref may be shown.
+func (a AccessRules) TableVisible(ref TableRef) bool {
+	for _, p := range a.tables.exclude {
+		if p.matchesTable(ref) {
+			return false
+		}
+	}
+	if len(a.tables.include) == 0 {
+		return true
+	}
+	for _, p := range a.tables.include {
+		if p.matchesTable(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnVisible reports whether column of ref may be shown.
+func (a AccessRules) ColumnVisible(ref TableRef, column string) bool {
+	for _, p := range a.columns.exclude {
+		if p.matchesColumn(ref, column) {
+			return false
+		}
+	}
+
+	restricted := false
+	for _, p := range a.columns.include {
+		if !p.matchesTable(ref) {
+			continue
+		}
+		restricted = true
+		if globMatch(p.column, column) {
+			return true
+		}
+	}
+	return !restricted
+}
+
+// VisibleColumns returns the columns of ref that may be shown.
+func (a AccessRules) VisibleColumns(ref TableRef, columns []types.Column) []types.Column {
+	if len(a.columns.include) == 0 && len(a.columns.exclude) == 0 {
+		return columns
+	}
+
+	visible := make([]types.Column, 0, len(columns))
+	for _, col := range columns {
+		if a.ColumnVisible(ref, col.Name) {
+			visible = append(visible, col)
+		}
+	}
+	return visible
+}
+
+// HidesColumns reports whether any of columns is hidden.
+func (a AccessRules) HidesColumns(ref TableRef, columns []types.Column) bool {
+	return len(a.VisibleColumns(ref, columns)) != len(columns)
+}
+
+// FilterDescription removes hidden columns from a table description,
+// along with the indexes that cover them.
+func (a AccessRules) FilterDescription(ref TableRef, description *types.TableDescription) {
+	description.Columns = a.VisibleColumns(ref, description.Columns)
+
+	var primaryKeys []string
+	for _, name := range description.PrimaryKeys {
+		if a.ColumnVisible(ref, name) {
+			primaryKeys = append(primaryKeys, name)
+		}
+	}
+	description.PrimaryKeys = primaryKeys
+
+	var indexes []types.Index
+	for _, index := range description.Indexes {
+		hidden := false
+		for _, name := range index.Columns {
+			if !a.ColumnVisible(ref, name) {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			indexes = append(indexes, index)
+		}
+	}
+	description.Indexes = indexes
+}
+
+// AccessLookup gives CheckQuery access to a connector's catalog.
+type AccessLookup struct {
+	// Resolve finds a table among the visible tables
+	Resolve func(name string) (TableRef, error)
+	// Columns returns every column of a table, hidden or not
+	Columns func(ref TableRef) ([]types.Column, error)
+}
+
+// catalogFunctions read the catalog, or read tables named by a string or
+// run a query given as a string, so the table rules cannot see what they
+// reach. They are matched by name without schema.
+var catalogFunctions = map[types.Dialect][]string{
+	types.DialectPostgres: {
+		// query_to_xml, table_to_xml, cursor_to_xml and the rest of the family
+		"*_to_xml", "*_to_xmlschema", "*_to_xml_and_xmlschema",
+		"ts_stat", "ts_rewrite", "dblink*",
+		"pg_get_*def", "pg_get_serial_sequence", "has_*privilege", "to_regclass",
+		"obj_description", "col_description", "pg_relation_size", "pg_total_relation_size",
+		"pg_table_size", "pg_indexes_size", "pg_relation_filenode", "pg_relation_filepath",
+		"pgstattuple*", "pg_visibility*", "currval", "pg_sequence_last_value",
+	},
+	types.DialectSQLite: {"pragma_*"},
+}
+
+// AccessViolation is returned by CheckQuery when a query reaches for a
+// hidden table or column.
+type AccessViolation struct {
+	err error
+}
+
+func (v *AccessViolation) Error() string {
+	return v.err.Error()
+}
+
+func (v *AccessViolation) Unwrap() error {
+	return v.err
+}
+
+func denied(format string, args ...any) error {
+	return &AccessViolation{err: fmt.Errorf(format, args...)}
+}
+
+// CheckQuery rejects a query that references a hidden table or column.
+// Every table the query reads must resolve among the visible tables, which
+// also keeps system catalogs such as information_schema out of reach.
+// Unqualified columns are checked against every table in the query, and a
+// star is rejected when its query block reads a table with hidden columns.
+func (a AccessRules) CheckQuery(dialect types.Dialect, query string, lookup AccessLookup) error {
+	if a.IsZero() {
+		return nil
+	}
+
+	refs, err := guard.ExtractReferences(dialect, query)
+	if err != nil {
+		return err
+	}
+	if refs.Opaque {
+		return denied("only SELECT queries are allowed while table or column rules are configured")
+	}
+	for _, fn := range refs.Functions {
+		name := fn[strings.LastIndex(fn, ".")+1:]
+		for _, pattern := range catalogFunctions[dialect] {
+			if globMatch(pattern, name) {
+				return denied("function %s reads the catalog or tables named in a string and is not allowed while table or column rules are configured", fn)
+			}
+		}
+	}
+
+	type queryTable struct {
+		ref     TableRef
+		alias   string
+		scope   int
+		columns []types.Column
+		hides   bool
+	}
+	var tables []queryTable
+	for _, t := range refs.Tables {
+		if refs.IsCTE(t) {
+			continue
+		}
+		ref, err := lookup.Resolve(t.Name)
+		if err != nil {
+			return denied("query references %s: %w", t.Name, err)
+		}
+		columns, err := lookup.Columns(ref)
+		if err != nil {
+			return err
+		}
+		hides := a.HidesColumns(ref, columns)
+		if hides && len(t.ColumnAliases) > 0 {
+			// The aliases rename the columns, hiding which one is read
+			return denied("column aliases are not allowed on %s because some of its columns are hidden", ref)
+		}
+		tables = append(tables, queryTable{
+			ref:     ref,
+			alias:   t.Alias,
+			scope:   t.Scope,
+			columns: columns,
+			hides:   hides,
+		})
+	}
+
+	// named finds the table a qualifier or whole-row reference points at
+	named := func(name string) (queryTable, bool) {
+		for _, t := range tables {
+			if t.alias != "" {
+				if strings.EqualFold(t.alias, name) {
+					return t, true
+				}
+				continue
+			}
+			if strings.EqualFold(t.ref.Name, name) || strings.EqualFold(t.ref.Schema+"."+t.ref.Name, name) {
+				return t, true
+			}
+		}
+		return queryTable{}, false
+	}
+	hidden := func(t queryTable, column string) bool {
+		for _, col := range t.columns {
+			if strings.EqualFold(col.Name, column) {
+				return !a.ColumnVisible(t.ref, col.Name)
+			}
+		}
+		return false
+	}
+
+	for _, col := range refs.Columns {
+		switch {
+		case col.Name == "*" && col.Qualifier == "":
+			for _, t := range tables {
+				if t.scope == col.Scope && t.hides {
+					return denied("SELECT * is not allowed on %s because some of its columns are hidden, list the columns instead", t.ref)
+				}
+			}
+		case col.Name == "*":
+			if t, ok := named(col.Qualifier); ok && t.hides {
+				return denied("%s.* is not allowed because some columns of %s are hidden, list the columns instead", col.Qualifier, t.ref)
+			}
+		case col.Qualifier != "":
+			if t, ok := named(col.Qualifier); ok && hidden(t, col.Name) {
+				return denied("column %s of %s is not available", col.Name, t.ref)
+			}
+		default:
+			// A bare table name or alias reads the whole row in PostgreSQL
+			if t, ok := named(col.Name); ok && t.hides {
+				return denied("whole-row reference %s is not allowed because some columns of %s are hidden", col.Name, t.ref)
+			}
+			for _, t := range tables {
+				if hidden(t, col.Name) {
+					return denied("column %s of %s is not available", col.Name, t.ref)
+				}
+			}
+		}
+	}
+
+	return nil
+}
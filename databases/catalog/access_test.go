@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+func TestCheckQuery(t *testing.T) {
+	rules, err := NewAccessRules(nil, []string{"cards"}, nil, []string{"users.ssn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := map[string][]types.Column{
+		"orders": {{Name: "id"}, {Name: "user_id"}},
+		"users":  {{Name: "id"}, {Name: "name"}, {Name: "ssn"}},
+	}
+	lookup := AccessLookup{
+		Resolve: func(name string) (TableRef, error) {
+			if _, ok := tables[name]; !ok {
+				return TableRef{}, fmt.Errorf("table %s not found", name)
+			}
+			return TableRef{Schema: "public", Name: name, Type: TypeBaseTable}, nil
+		},
+		Columns: func(ref TableRef) ([]types.Column, error) {
+			return tables[ref.Name], nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		dialect types.Dialect
+		query   string
+		allowed bool
+	}{
+		{"visible table", types.DialectPostgres, "SELECT id FROM orders", true},
+		{"visible columns", types.DialectPostgres, "SELECT u.id, u.name FROM users u", true},
+		{"cte", types.DialectPostgres, "WITH cards AS (SELECT id FROM orders) SELECT * FROM cards", true},
+		{"hidden table", types.DialectPostgres, "SELECT * FROM cards", false},
+		{"hidden column", types.DialectPostgres, "SELECT ssn FROM users", false},
+		{"star over hidden column", types.DialectPostgres, "SELECT * FROM users", false},
+		{"cte in another block", types.DialectPostgres, "SELECT c.* FROM cards c, (WITH cards AS (SELECT 1) SELECT 1) s", false},
+		{"cte reading itself", types.DialectPostgres, "WITH cards AS (SELECT * FROM cards) SELECT * FROM cards", false},
+		{"table_to_xml", types.DialectPostgres, "SELECT table_to_xml('cards', true, false, '')", false},
+		{"query_to_xml", types.DialectPostgres, "SELECT query_to_xml('select * from cards', true, false, '')", false},
+		{"qualified query_to_xml", types.DialectPostgres, `SELECT pg_catalog."query_to_xml"('select 1', true, false, '')`, false},
+		{"cursor_to_xml", types.DialectPostgres, "SELECT cursor_to_xml('c', 10, true, false, '')", false},
+		{"ts_stat", types.DialectPostgres, "SELECT * FROM ts_stat('select v from cards')", false},
+		{"view definition", types.DialectPostgres, "SELECT pg_get_viewdef('secret_view')", false},
+		{"pragma function", types.DialectSQLite, "SELECT * FROM pragma_table_info('cards')", false},
+		{"system catalog", types.DialectPostgres, "SELECT * FROM information_schema.columns", false},
+		{"opaque statement", types.DialectMySQL, "SHOW TABLES", false},
+		{"table statement", types.DialectPostgres, "TABLE orders", true},
+		{"union table", types.DialectPostgres, "SELECT 1 UNION TABLE cards", false},
+		{"table in exists", types.DialectPostgres, "SELECT * FROM orders WHERE EXISTS (TABLE cards)", false},
+		{"table in cte", types.DialectPostgres, "WITH c AS (TABLE cards) SELECT * FROM c", false},
+		{"union table with hidden column", types.DialectPostgres, "SELECT id, name, id FROM users UNION TABLE users", false},
+		{"union visible table", types.DialectPostgres, "SELECT id, name FROM users UNION TABLE orders", true},
+		{"straight_join", types.DialectMySQL, "SELECT c.number FROM orders STRAIGHT_JOIN cards c", false},
+		{"select straight_join", types.DialectMySQL, "SELECT STRAIGHT_JOIN id FROM orders", true},
+		{"column alias list", types.DialectPostgres, "SELECT c FROM users AS u(a, b, c)", false},
+		{"column alias list without hidden columns", types.DialectPostgres, "SELECT a FROM orders AS o(a, b)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.CheckQuery(tt.dialect, tt.query, lookup)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("CheckQuery() error = %v, want nil", err)
+				}
+				return
+			}
+			var violation *AccessViolation
+			if !errors.As(err, &violation) {
+				t.Fatalf("CheckQuery() error = %v, want an *AccessViolation", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package catalog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// Cache holds a connector's catalog between calls: the visible tables, the
+// schema names, the default schema, the declared foreign keys and the
+// columns of each table. Entries expire after the TTL and can be
+// invalidated per table. A non-positive TTL disables caching.
+type Cache struct {
+	ttl time.Duration
+
+	mu            sync.Mutex
+	tables        cacheEntry[[]TableRef]
+	schemas       cacheEntry[[]string]
+	defaultSchema cacheEntry[string]
+	foreignKeys   cacheEntry[[]ForeignKey]
+	columns       map[tableKey]cacheEntry[[]types.Column]
+}
+
+type cacheEntry[T any] struct {
+	value   T
+	expires time.Time
+	valid   bool
+}
+
+func (e cacheEntry[T]) fresh() bool {
+	return e.valid && time.Now().Before(e.expires)
+}
+
+// tableKey identifies a table regardless of its type.
+type tableKey struct {
+	schema, name string
+}
+
+// NewCache creates a cache whose entries live for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		columns: make(map[tableKey]cacheEntry[[]types.Column]),
+	}
+}
+
+// Tables returns the cached table list, calling load when it has expired.
+func (c *Cache) Tables(load func() ([]TableRef, error)) ([]TableRef, error) {
+	return cached(c, &c.tables, load)
+}
+
+// Schemas returns the cached schema names, calling load when they have
+// expired.
+func (c *Cache) Schemas(load func() ([]string, error)) ([]string, error) {
+	return cached(c, &c.schemas, load)
+}
+
+// DefaultSchema returns the cached schema unqualified names resolve in,
+// calling load when it has expired.
+func (c *Cache) DefaultSchema(load func() (string, error)) (string, error) {
+	return cached(c, &c.defaultSchema, load)
+}
+
+// ForeignKeys returns the cached declared foreign keys, calling load when
+// they have expired.
+func (c *Cache) ForeignKeys(load func() ([]ForeignKey, error)) ([]ForeignKey, error) {
+	return cached(c, &c.foreignKeys, load)
+}
+
+// Columns returns the columns of every table in refs, in the same order.
+// Tables whose columns are not cached are loaded together with one call to
+// load, which reports each column it reads through add.
+func (c *Cache) Columns(refs []TableRef, load func(missing []TableRef, add func(schema, name string, column types.Column)) error) ([][]types.Column, error) {
+	columns := make([][]types.Column, len(refs))
+
+	var missing []TableRef
+	var missingAt []int
+	c.mu.Lock()
+	for i, ref := range refs {
+		if entry := c.columns[keyOf(ref)]; entry.fresh() {
+			columns[i] = entry.value
+			continue
+		}
+		missing = append(missing, ref)
+		missingAt = append(missingAt, i)
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return columns, nil
+	}
+
+	loaded := make(map[tableKey][]types.Column, len(missing))
+	err := load(missing, func(schema, name string, column types.Column) {
+		key := tableKey{schema: schema, name: name}
+		loaded[key] = append(loaded[key], column)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(c.ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, i := range missingAt {
+		key := keyOf(refs[i])
+		columns[i] = loaded[key]
+		if c.ttl > 0 {
+			c.columns[key] = cacheEntry[[]types.Column]{value: loaded[key], expires: expires, valid: true}
+		}
+	}
+	return columns, nil
+}
+
+func keyOf(ref TableRef) tableKey {
+	return tableKey{schema: ref.Schema, name: ref.Name}
+}
+
+func cached[T any](c *Cache, entry *cacheEntry[T], load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	current := *entry
+	c.mu.Unlock()
+	if current.fresh() {
+		return current.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	if c.ttl > 0 {
+		c.mu.Lock()
+		*entry = cacheEntry[T]{value: value, expires: time.Now().Add(c.ttl), valid: true}
+		c.mu.Unlock()
+	}
+	return value, nil
+}
+
+// Reset drops every cached entry.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tables = cacheEntry[[]TableRef]{}
+	c.schemas = cacheEntry[[]string]{}
+	c.defaultSchema = cacheEntry[string]{}
+	c.foreignKeys = cacheEntry[[]ForeignKey]{}
+	c.columns = make(map[tableKey]cacheEntry[[]types.Column])
+}
+
+// Refresh invalidates the named tables. The table list and foreign keys are
+// always reloaded so new and dropped tables are picked up; columns are
+// dropped only for the requested tables, which resolve resolves against the
+// fresh list. With no names the whole cache is reset.
+func (c *Cache) Refresh(names []string, resolve func(name string) (TableRef, error)) ([]TableRef, error) {
+	if len(names) == 0 {
+		c.Reset()
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	c.tables = cacheEntry[[]TableRef]{}
+	c.schemas = cacheEntry[[]string]{}
+	c.foreignKeys = cacheEntry[[]ForeignKey]{}
+	c.mu.Unlock()
+
+	refs := make([]TableRef, 0, len(names))
+	for _, name := range names {
+		ref, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		delete(c.columns, keyOf(ref))
+		c.mu.Unlock()
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
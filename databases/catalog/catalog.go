@@ -0,0 +1,278 @@
+// Package catalog resolves user supplied table names against the database
+// catalog and renders them as safely quoted identifiers for each dialect.
+//
+// Table names reach the connectors from an LLM, so they must never be
+// formatted into SQL as-is. Connectors look the requested name up in the
+// list of tables the catalog reports and only ever quote the catalog's own
+// spelling of that name.
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// TableRef identifies a table by its schema (PostgreSQL schema, MySQL
+// database or SQLite attached database) and name, exactly as the catalog
+// spells them.
+type TableRef struct {
+	Schema string `db:"table_schema" json:"schema"`
+	Name   string `db:"table_name" json:"name"`
+	// Type is the information_schema table type, e.g. BASE TABLE or VIEW
+	Type string `db:"table_type" json:"type"`
+}
+
+const (
+	TypeBaseTable        = "BASE TABLE"
+	TypeView             = "VIEW"
+	TypeMaterializedView = "MATERIALIZED VIEW"
+	// typeSystemView is MySQL's type for information_schema views
+	typeSystemView = "SYSTEM VIEW"
+)
+
+// Kind returns the object kind of the table: table, view or materialized
+// view.
+func (r TableRef) Kind() string {
+	switch r.Type {
+	case TypeView, typeSystemView:
+		return types.KindView
+	case TypeMaterializedView:
+		return types.KindMaterializedView
+	default:
+		return types.KindTable
+	}
+}
+
+// String returns the unquoted schema.name form.
+func (r TableRef) String() string {
+	if r.Schema == "" {
+		return r.Name
+	}
+	return r.Schema + "." + r.Name
+}
+
+// Ident returns the schema.name form, quoting only the parts that would not
+// otherwise parse back to this table, e.g. names containing dots.
+func (r TableRef) Ident(dialect types.Dialect) string {
+	name := identPart(dialect, r.Name)
+	if r.Schema == "" {
+		return name
+	}
+	return identPart(dialect, r.Schema) + "." + name
+}
+
+func identPart(dialect types.Dialect, part string) string {
+	for _, ch := range part {
+		if ch != '_' && ch != '$' && (ch < '0' || ch > '9') && (ch < 'a' || ch > 'z') && (ch < 'A' || ch > 'Z') {
+			return QuoteIdent(dialect, part)
+		}
+	}
+	if part == "" {
+		return QuoteIdent(dialect, part)
+	}
+	return part
+}
+
+// Quote returns the schema-qualified identifier quoted for dialect.
+func (r TableRef) Quote(dialect types.Dialect) string {
+	if r.Schema == "" {
+		return QuoteIdent(dialect, r.Name)
+	}
+	return QuoteIdent(dialect, r.Schema) + "." + QuoteIdent(dialect, r.Name)
+}
+
+// QuoteIdent quotes a single identifier, escaping any embedded quote
+// characters by doubling them.
+func QuoteIdent(dialect types.Dialect, name string) string {
+	if dialect == types.DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ParseName splits a possibly schema-qualified, possibly quoted table name
+// into its schema and table parts. Dots inside quotes are part of the name.
+func ParseName(dialect types.Dialect, raw string) (schema, name string, quoted bool, err error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", "", false, fmt.Errorf("table name is empty")
+	}
+
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		closing, isQuote := closingQuote(dialect, ch)
+		switch {
+		case isQuote:
+			quoted = true
+			i++
+			for ; i < len(raw); i++ {
+				if raw[i] == closing {
+					if i+1 < len(raw) && raw[i+1] == closing && closing != ']' {
+						current.WriteByte(closing)
+						i++
+						continue
+					}
+					break
+				}
+				current.WriteByte(raw[i])
+			}
+			if i >= len(raw) {
+				return "", "", false, fmt.Errorf("unterminated quoted identifier in %q", raw)
+			}
+		case ch == '.':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	parts = append(parts, current.String())
+
+	for _, part := range parts {
+		if part == "" {
+			return "", "", false, fmt.Errorf("invalid table name %q", raw)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return "", parts[0], quoted, nil
+	case 2:
+		return parts[0], parts[1], quoted, nil
+	default:
+		return "", "", false, fmt.Errorf("invalid table name %q: expected table or schema.table", raw)
+	}
+}
+
+func closingQuote(dialect types.Dialect, ch byte) (byte, bool) {
+	switch {
+	case ch == '"' && dialect != types.DialectMySQL:
+		return '"', true
+	case ch == '`' && dialect != types.DialectPostgres:
+		return '`', true
+	case ch == '[' && dialect == types.DialectSQLite:
+		return ']', true
+	}
+	return 0, false
+}
+
+// NotFoundError is returned when a requested table does not exist.
+type NotFoundError struct {
+	Name       string
+	Suggestion string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("table %s not found, did you mean %s?", e.Name, e.Suggestion)
+	}
+	return fmt.Sprintf("table %s not found", e.Name)
+}
+
+// Resolve finds the table a caller asked for among the catalog's tables.
+// Unqualified names are looked up in defaultSchema first and then across
+// all schemas. An exact match always wins; an unquoted name may fall back to
+// a unique case-insensitive match.
+func Resolve(dialect types.Dialect, requested string, tables []TableRef, defaultSchema string) (TableRef, error) {
+	schema, name, quoted, err := ParseName(dialect, requested)
+	if err != nil {
+		return TableRef{}, err
+	}
+
+	match := func(equal func(a, b string) bool) ([]TableRef, bool) {
+		var found []TableRef
+		for _, t := range tables {
+			if !equal(t.Name, name) {
+				continue
+			}
+			if schema != "" && !equal(t.Schema, schema) {
+				continue
+			}
+			if schema == "" && t.Schema == defaultSchema {
+				return []TableRef{t}, true
+			}
+			found = append(found, t)
+		}
+		return found, false
+	}
+
+	exact := func(a, b string) bool { return a == b }
+	if found, preferred := match(exact); preferred || len(found) == 1 {
+		return found[0], nil
+	} else if len(found) > 1 {
+		return TableRef{}, ambiguous(requested, found)
+	}
+
+	if !quoted {
+		if found, preferred := match(strings.EqualFold); preferred || len(found) == 1 {
+			return found[0], nil
+		} else if len(found) > 1 {
+			return TableRef{}, ambiguous(requested, found)
+		}
+	}
+
+	return TableRef{}, &NotFoundError{Name: requested, Suggestion: suggest(requested, schema, name, tables)}
+}
+
+func ambiguous(requested string, found []TableRef) error {
+	names := make([]string, len(found))
+	for i, t := range found {
+		names[i] = t.String()
+	}
+	sort.Strings(names)
+	return fmt.Errorf("table name %s is ambiguous, qualify it with a schema: %s", requested, strings.Join(names, ", "))
+}
+
+// suggest returns the catalog name closest to the requested one, or "" if
+// nothing is close enough to be a plausible typo.
+func suggest(requested, schema, name string, tables []TableRef) string {
+	target := strings.ToLower(name)
+	best, bestScore := "", -1
+	for _, t := range tables {
+		candidate := strings.ToLower(t.Name)
+		score := levenshtein(target, candidate)
+		if schema != "" && !strings.EqualFold(schema, t.Schema) {
+			score++
+		}
+		if strings.Contains(candidate, target) || strings.Contains(target, candidate) {
+			score = min(score, 1)
+		}
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = t.String(), score
+			if schema == "" {
+				best = t.Name
+			}
+		}
+	}
+
+	threshold := max(2, len(target)/3)
+	if bestScore < 0 || bestScore > threshold || best == requested {
+		return ""
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
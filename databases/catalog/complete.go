@@ -0,0 +1,94 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+)
+
+// Completion match kinds, best first.
+const (
+	matchPrefix = iota
+	matchPartPrefix
+	matchSubstring
+	matchFuzzy
+	noMatch
+)
+
+// Complete returns the candidates matching value, best matches first:
+// case-insensitive prefix matches, then matches at the start of a dotted or
+// underscore separated part (so "ord" finds "sales.orders" and
+// "line_orders"), then substring matches, then fuzzy matches where the
+// characters of value appear in order. An empty value matches everything.
+func Complete(candidates []string, value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	type ranked struct {
+		candidate string
+		kind      int
+	}
+	var matches []ranked
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		if kind := matchKind(strings.ToLower(c), value); kind != noMatch {
+			matches = append(matches, ranked{candidate: c, kind: kind})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].kind != matches[j].kind {
+			return matches[i].kind < matches[j].kind
+		}
+		if len(matches[i].candidate) != len(matches[j].candidate) {
+			return len(matches[i].candidate) < len(matches[j].candidate)
+		}
+		return matches[i].candidate < matches[j].candidate
+	})
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.candidate
+	}
+	return result
+}
+
+func matchKind(candidate, value string) int {
+	switch {
+	case value == "" || strings.HasPrefix(candidate, value):
+		return matchPrefix
+	case partPrefix(candidate, value):
+		return matchPartPrefix
+	case strings.Contains(candidate, value):
+		return matchSubstring
+	case subsequence(candidate, value):
+		return matchFuzzy
+	}
+	return noMatch
+}
+
+// partPrefix reports whether value starts any part of candidate after a
+// separator or an opening quote.
+func partPrefix(candidate, value string) bool {
+	for i := 0; i < len(candidate); i++ {
+		switch candidate[i] {
+		case '.', '_', '"', '`':
+			if strings.HasPrefix(strings.TrimLeft(candidate[i+1:], "\"`"), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func subsequence(candidate, value string) bool {
+	i := 0
+	for j := 0; j < len(candidate) && i < len(value); j++ {
+		if candidate[j] == value[i] {
+			i++
+		}
+	}
+	return i == len(value)
+}
@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// maxDiagramTables bounds the tables drawn in one diagram.
+const maxDiagramTables = 100
+
+// DiagramTables selects the tables of a diagram from tables: those in only
+// and those within hops relationships of focus, or every table when
+// neither is given. Inferred keys count as relationships when inferred is
+// set.
+func DiagramTables(tables []TableRef, keys []ForeignKey, only []TableRef, focus *TableRef, hops int, inferred bool) ([]TableRef, error) {
+	selected := tables
+	if len(only) > 0 || focus != nil {
+		wanted := make(map[tableKey]bool)
+		for _, ref := range only {
+			wanted[keyOf(ref)] = true
+		}
+		if focus != nil {
+			for key, d := range newJoinGraph(keys, inferred).distances(keyOf(*focus)) {
+				if d <= hops {
+					wanted[key] = true
+				}
+			}
+		}
+
+		selected = nil
+		for _, t := range tables {
+			if wanted[keyOf(t)] {
+				selected = append(selected, t)
+			}
+		}
+	}
+
+	if len(selected) > maxDiagramTables {
+		return nil, fmt.Errorf("%d tables selected but a diagram shows at most %d; name the tables to draw or a focus table", len(selected), maxDiagramTables)
+	}
+	return selected, nil
+}
+
+// SchemaGraph collects the columns, primary keys and relationships of
+// tables for a diagram. columns holds the visible columns of each table;
+// primary key columns not among them are left out.
+func SchemaGraph(dialect types.Dialect, tables []TableRef, columns [][]types.Column, primaryKeys map[TableRef][]string, keys []ForeignKey, inferred bool) *types.SchemaGraph {
+	counts := make(map[string]int)
+	for _, t := range tables {
+		counts[t.Name]++
+	}
+	names := make(map[tableKey]string, len(tables))
+	for _, t := range tables {
+		names[keyOf(t)] = t.Name
+		if counts[t.Name] > 1 {
+			names[keyOf(t)] = t.Ident(dialect)
+		}
+	}
+
+	graph := &types.SchemaGraph{
+		Tables:        make([]types.GraphTable, 0, len(tables)),
+		Relationships: []types.Relationship{},
+	}
+	for i, t := range tables {
+		visible := make(map[string]bool, len(columns[i]))
+		for _, column := range columns[i] {
+			visible[column.Name] = true
+		}
+
+		table := types.GraphTable{Name: names[keyOf(t)], Columns: columns[i]}
+		for _, name := range primaryKeys[TableRef{Schema: t.Schema, Name: t.Name}] {
+			if visible[name] {
+				table.PrimaryKeys = append(table.PrimaryKeys, name)
+			}
+		}
+		graph.Tables = append(graph.Tables, table)
+	}
+
+	for _, key := range keys {
+		from, ok1 := names[keyOf(key.Table)]
+		to, ok2 := names[keyOf(key.Referenced)]
+		if !ok1 || !ok2 || (key.Inferred && !inferred) {
+			continue
+		}
+		relationship := key.Relationship(dialect)
+		relationship.Table, relationship.ReferencedTable = from, to
+		graph.Relationships = append(graph.Relationships, relationship)
+	}
+	return graph
+}
@@ -0,0 +1,299 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+const (
+	// maxJoinHops bounds the joins used to reach one table
+	maxJoinHops = 6
+	// maxJoinCandidates bounds the alternative paths considered for each
+	// table added to a join
+	maxJoinCandidates = 200
+	// defaultJoinPaths is how many paths JoinPaths returns by default
+	defaultJoinPaths = 3
+)
+
+// joinEdge is a key seen from one of its tables. manyToOne is set when the
+// edge leaves the referencing table, so the join lands on the referenced
+// (unique) columns and cannot repeat rows.
+type joinEdge struct {
+	key       ForeignKey
+	to        TableRef
+	manyToOne bool
+}
+
+// joinGraph links tables through their keys in both directions.
+type joinGraph map[tableKey][]joinEdge
+
+func newJoinGraph(keys []ForeignKey, inferred bool) joinGraph {
+	g := make(joinGraph)
+	for _, key := range keys {
+		if (key.Inferred && !inferred) || keyOf(key.Table) == keyOf(key.Referenced) {
+			continue
+		}
+		g[keyOf(key.Table)] = append(g[keyOf(key.Table)], joinEdge{key: key, to: key.Referenced, manyToOne: true})
+		g[keyOf(key.Referenced)] = append(g[keyOf(key.Referenced)], joinEdge{key: key, to: key.Table})
+	}
+	return g
+}
+
+// distances returns the number of joins from every table that can reach
+// target.
+func (g joinGraph) distances(target tableKey) map[tableKey]int {
+	dist := map[tableKey]int{target: 0}
+	queue := []tableKey{target}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, e := range g[t] {
+			if _, ok := dist[keyOf(e.to)]; !ok {
+				dist[keyOf(e.to)] = dist[t] + 1
+				queue = append(queue, keyOf(e.to))
+			}
+		}
+	}
+	return dist
+}
+
+// paths lists the ways to reach target from the tables joined so far with
+// at most one join more than the shortest way, passing through no other
+// joined table.
+func (g joinGraph) paths(joined []TableRef, target tableKey) [][]joinEdge {
+	dist := g.distances(target)
+	inJoin := make(map[tableKey]bool, len(joined))
+	shortest := -1
+	for _, t := range joined {
+		inJoin[keyOf(t)] = true
+		if d, ok := dist[keyOf(t)]; ok && (shortest < 0 || d < shortest) {
+			shortest = d
+		}
+	}
+	if shortest < 0 || shortest > maxJoinHops {
+		return nil
+	}
+	budget := min(shortest+1, maxJoinHops)
+
+	var found [][]joinEdge
+	visited := make(map[tableKey]bool)
+	var walk func(t tableKey, steps []joinEdge)
+	walk = func(t tableKey, steps []joinEdge) {
+		if t == target {
+			found = append(found, append([]joinEdge(nil), steps...))
+			return
+		}
+		for _, e := range g[t] {
+			next := keyOf(e.to)
+			d, ok := dist[next]
+			if !ok || visited[next] || inJoin[next] || len(steps)+1+d > budget || len(found) >= maxJoinCandidates {
+				continue
+			}
+			visited[next] = true
+			walk(next, append(steps, e))
+			visited[next] = false
+		}
+	}
+	for _, t := range joined {
+		if _, ok := dist[keyOf(t)]; ok {
+			visited[keyOf(t)] = true
+			walk(keyOf(t), nil)
+			visited[keyOf(t)] = false
+		}
+	}
+	return found
+}
+
+// joinScore ranks join paths: fewer joins first, then fewer joins that can
+// repeat rows, then fewer inferred keys.
+type joinScore struct {
+	joins, oneToMany, inferred int
+}
+
+func scoreJoins(steps []joinEdge) joinScore {
+	s := joinScore{joins: len(steps)}
+	for _, e := range steps {
+		if !e.manyToOne {
+			s.oneToMany++
+		}
+		if e.key.Inferred {
+			s.inferred++
+		}
+	}
+	return s
+}
+
+func (s joinScore) less(o joinScore) bool {
+	if s.joins != o.joins {
+		return s.joins < o.joins
+	}
+	if s.oneToMany != o.oneToMany {
+		return s.oneToMany < o.oneToMany
+	}
+	return s.inferred < o.inferred
+}
+
+func sortJoins(paths [][]joinEdge) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return scoreJoins(paths[i]).less(scoreJoins(paths[j]))
+	})
+}
+
+// JoinPaths suggests ways to join tables through keys, best first. The
+// first table is joined to the nearest of the others, and so on until all
+// are joined; the alternatives differ in the path taken to the first
+// table added. Inferred keys are only used when opts.IncludeInferred is
+// set.
+func JoinPaths(dialect types.Dialect, keys []ForeignKey, tables []TableRef, opts types.JoinPathOptions) ([]types.JoinPath, error) {
+	var unique []TableRef
+	seen := make(map[tableKey]bool)
+	for _, t := range tables {
+		if !seen[keyOf(t)] {
+			seen[keyOf(t)] = true
+			unique = append(unique, t)
+		}
+	}
+	if len(unique) < 2 {
+		return nil, fmt.Errorf("at least two different tables are needed")
+	}
+
+	maxPaths := opts.MaxPaths
+	if maxPaths <= 0 {
+		maxPaths = defaultJoinPaths
+	}
+
+	g := newJoinGraph(keys, opts.IncludeInferred)
+	root := unique[0]
+	first, err := g.nextJoin([]TableRef{root}, unique[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	var results []types.JoinPath
+	var scores []joinScore
+	rendered := make(map[string]bool)
+	for _, candidate := range first {
+		steps, err := g.complete(root, candidate, unique[1:])
+		if err != nil {
+			return nil, err
+		}
+		path := renderJoinPath(dialect, root, steps)
+		if rendered[path.SQL] {
+			continue
+		}
+		rendered[path.SQL] = true
+		results = append(results, path)
+		scores = append(scores, scoreJoins(steps))
+	}
+
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]].less(scores[order[j]])
+	})
+
+	ranked := make([]types.JoinPath, 0, min(maxPaths, len(order)))
+	for _, i := range order[:min(maxPaths, len(order))] {
+		ranked = append(ranked, results[i])
+	}
+	return ranked, nil
+}
+
+// nextJoin picks the table in remaining nearest to the joined tables and
+// returns the ranked paths to it.
+func (g joinGraph) nextJoin(joined, remaining []TableRef) ([][]joinEdge, error) {
+	var best [][]joinEdge
+	for _, t := range remaining {
+		paths := g.paths(joined, keyOf(t))
+		if len(paths) == 0 {
+			names := make([]string, len(joined))
+			for i, j := range joined {
+				names[i] = j.String()
+			}
+			return nil, fmt.Errorf("no relationships connect %s to %s within %d joins", t, strings.Join(names, ", "), maxJoinHops)
+		}
+		sortJoins(paths)
+		if best == nil || scoreJoins(paths[0]).less(scoreJoins(best[0])) {
+			best = paths
+		}
+	}
+	return best, nil
+}
+
+// complete joins the remaining tables after first, always taking the best
+// path to the nearest table still missing.
+func (g joinGraph) complete(root TableRef, first []joinEdge, remaining []TableRef) ([]joinEdge, error) {
+	steps := append([]joinEdge(nil), first...)
+	joined := []TableRef{root}
+	for _, e := range first {
+		joined = append(joined, e.to)
+	}
+
+	for {
+		var missing []TableRef
+		for _, t := range remaining {
+			if !containsTable(joined, t) {
+				missing = append(missing, t)
+			}
+		}
+		if len(missing) == 0 {
+			return steps, nil
+		}
+
+		paths, err := g.nextJoin(joined, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range paths[0] {
+			steps = append(steps, e)
+			joined = append(joined, e.to)
+		}
+	}
+}
+
+func containsTable(tables []TableRef, t TableRef) bool {
+	for _, other := range tables {
+		if keyOf(other) == keyOf(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderJoinPath writes the path as a FROM clause with one JOIN per step.
+func renderJoinPath(dialect types.Dialect, root TableRef, steps []joinEdge) types.JoinPath {
+	score := scoreJoins(steps)
+	path := types.JoinPath{
+		Tables:         []string{root.Ident(dialect)},
+		Joins:          score.joins,
+		OneToManyJoins: score.oneToMany,
+		InferredJoins:  score.inferred,
+		Relationships:  make([]types.Relationship, 0, len(steps)),
+	}
+
+	var b strings.Builder
+	b.WriteString("FROM " + root.Quote(dialect))
+	for _, e := range steps {
+		var conditions []string
+		for i, column := range e.key.Columns {
+			referencing := e.key.Table.Quote(dialect) + "." + QuoteIdent(dialect, column)
+			referenced := e.key.Referenced.Quote(dialect) + "." + QuoteIdent(dialect, e.key.ReferencedColumns[i])
+			if e.manyToOne {
+				conditions = append(conditions, referenced+" = "+referencing)
+			} else {
+				conditions = append(conditions, referencing+" = "+referenced)
+			}
+		}
+		b.WriteString("\nJOIN " + e.to.Quote(dialect) + " ON " + strings.Join(conditions, " AND "))
+
+		path.Tables = append(path.Tables, e.to.Ident(dialect))
+		path.Relationships = append(path.Relationships, e.key.Relationship(dialect))
+	}
+	path.SQL = b.String()
+	return path
+}
@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"path"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// MatchResult holds the tables selected by a list of requested names along
+// with the requests that matched nothing.
+type MatchResult struct {
+	Tables   []TableRef
+	NotFound []string
+	// Suggestions maps a not-found request to the closest catalog name
+	Suggestions map[string]string
+}
+
+// Match selects tables by name. Each requested name may be schema-qualified
+// and may contain the glob wildcards * and ?; plain names are resolved the
+// same way as Resolve. Tables are returned once each, in catalog order.
+func Match(dialect types.Dialect, requested []string, tables []TableRef, defaultSchema string) MatchResult {
+	var result MatchResult
+	selected := make(map[TableRef]bool)
+
+	for _, raw := range requested {
+		if !strings.ContainsAny(raw, "*?") {
+			ref, err := Resolve(dialect, raw, tables, defaultSchema)
+			if err != nil {
+				result.addNotFound(raw, err)
+				continue
+			}
+			selected[ref] = true
+			continue
+		}
+
+		schema, name, _, err := ParseName(dialect, raw)
+		if err != nil {
+			result.addNotFound(raw, err)
+			continue
+		}
+
+		found := false
+		for _, t := range tables {
+			if !globMatch(name, t.Name) {
+				continue
+			}
+			if schema != "" && !globMatch(schema, t.Schema) {
+				continue
+			}
+			selected[t] = true
+			found = true
+		}
+		if !found {
+			result.NotFound = append(result.NotFound, raw)
+		}
+	}
+
+	for _, t := range tables {
+		if selected[t] {
+			result.Tables = append(result.Tables, t)
+		}
+	}
+	return result
+}
+
+func (r *MatchResult) addNotFound(raw string, err error) {
+	r.NotFound = append(r.NotFound, raw)
+	if nf, ok := err.(*NotFoundError); ok && nf.Suggestion != "" {
+		if r.Suggestions == nil {
+			r.Suggestions = make(map[string]string)
+		}
+		r.Suggestions[raw] = nf.Suggestion
+	}
+}
+
+// globMatch matches case-insensitively so "Order_*" finds "order_items".
+func globMatch(pattern, name string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
@@ -0,0 +1,127 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// objectKinds are the kinds of SchemaObject a connector lists.
+var objectKinds = []string{types.KindView, types.KindMaterializedView, types.KindFunction, types.KindProcedure, types.KindTrigger}
+
+// VisibleObjects drops the views missing from tables, which holds the
+// visible tables and views, and the triggers on tables missing from it.
+// Routines are kept; connectors only read them from visible schemas.
+func VisibleObjects(objects []types.SchemaObject, tables []TableRef) []types.SchemaObject {
+	visible := make(map[tableKey]bool, len(tables))
+	for _, t := range tables {
+		visible[keyOf(t)] = true
+	}
+
+	var kept []types.SchemaObject
+	for _, object := range objects {
+		switch object.Kind {
+		case types.KindView, types.KindMaterializedView:
+			if !visible[tableKey{schema: object.Schema, name: object.Name}] {
+				continue
+			}
+		case types.KindTrigger:
+			if !visible[tableKey{schema: object.Schema, name: object.Table}] {
+				continue
+			}
+		}
+		kept = append(kept, object)
+	}
+	return kept
+}
+
+// FilterObjects returns the objects of the given kinds, or of every kind
+// when none are given, without their definitions.
+func FilterObjects(objects []types.SchemaObject, kinds []string) ([]types.SchemaObject, error) {
+	wanted := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		kind, err := objectKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		wanted[kind] = true
+	}
+
+	filtered := []types.SchemaObject{}
+	for _, object := range objects {
+		if len(wanted) == 0 || wanted[object.Kind] {
+			object.Definition = ""
+			filtered = append(filtered, object)
+		}
+	}
+	return filtered, nil
+}
+
+// FindObjects returns the objects called requested, with their
+// definitions. Several objects may match: overloaded functions, or objects
+// of different kinds sharing a name; kind narrows them down. Names are
+// matched like Resolve matches tables.
+func FindObjects(dialect types.Dialect, objects []types.SchemaObject, requested, kind, defaultSchema string) ([]types.SchemaObject, error) {
+	if kind != "" {
+		var err error
+		if kind, err = objectKind(kind); err != nil {
+			return nil, err
+		}
+	}
+	schema, name, quoted, err := ParseName(dialect, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(equal func(a, b string) bool) []types.SchemaObject {
+		var found, preferred []types.SchemaObject
+		for _, object := range objects {
+			if !equal(object.Name, name) || (schema != "" && !equal(object.Schema, schema)) || (kind != "" && object.Kind != kind) {
+				continue
+			}
+			found = append(found, object)
+			if schema == "" && object.Schema == defaultSchema {
+				preferred = append(preferred, object)
+			}
+		}
+		if len(preferred) > 0 {
+			return preferred
+		}
+		return found
+	}
+
+	found := match(func(a, b string) bool { return a == b })
+	if len(found) == 0 && !quoted {
+		found = match(strings.EqualFold)
+	}
+	if len(found) > 0 {
+		return found, nil
+	}
+
+	described := "view, function, procedure or trigger"
+	if kind != "" {
+		described = kind
+	}
+	refs := make([]TableRef, 0, len(objects))
+	for _, object := range objects {
+		if kind == "" || object.Kind == kind {
+			refs = append(refs, TableRef{Schema: object.Schema, Name: object.Name})
+		}
+	}
+	if suggestion := suggest(requested, schema, name, refs); suggestion != "" {
+		return nil, fmt.Errorf("no %s named %s found, did you mean %s?", described, requested, suggestion)
+	}
+	return nil, fmt.Errorf("no %s named %s found", described, requested)
+}
+
+// objectKind normalizes a kind given by a caller.
+func objectKind(kind string) (string, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(kind, "_", " "))), " ")
+	for _, k := range objectKinds {
+		if normalized == k || normalized == k+"s" {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown object kind %q, use one of: %s", kind, strings.Join(objectKinds, ", "))
+}
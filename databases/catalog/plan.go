@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// PlanText returns the single value of a one-row, one-column EXPLAIN
+// result, as PostgreSQL and MySQL return their JSON plans.
+func PlanText(result *types.QueryResult) (string, error) {
+	if len(result.Rows) != 1 || len(result.Rows[0]) != 1 {
+		return "", fmt.Errorf("unexpected EXPLAIN output: %d rows", len(result.Rows))
+	}
+	for _, value := range result.Rows[0] {
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		default:
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		}
+	}
+	return "", nil
+}
+
+// PlanNumber converts a number from a plan, which may be encoded as a JSON
+// number or a string, and returns nil for anything else.
+func PlanNumber(value any) *float64 {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil
+		}
+		f = parsed
+	default:
+		return nil
+	}
+	return &f
+}
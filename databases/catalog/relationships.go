@@ -0,0 +1,207 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// ForeignKey is a relationship between two catalog tables, either declared
+// in the database or inferred from column names.
+type ForeignKey struct {
+	Name              string
+	Table             TableRef
+	Columns           []string
+	Referenced        TableRef
+	ReferencedColumns []string
+	OnUpdate          string
+	OnDelete          string
+	Inferred          bool
+}
+
+// Relationship renders the key with table names in dialect's identifier
+// form.
+func (k ForeignKey) Relationship(dialect types.Dialect) types.Relationship {
+	return types.Relationship{
+		Name:              k.Name,
+		Table:             k.Table.Ident(dialect),
+		Columns:           k.Columns,
+		ReferencedTable:   k.Referenced.Ident(dialect),
+		ReferencedColumns: k.ReferencedColumns,
+		OnUpdate:          k.OnUpdate,
+		OnDelete:          k.OnDelete,
+		Inferred:          k.Inferred,
+	}
+}
+
+// KeyColumn is one column pair of a declared foreign key, as read from the
+// catalog. ID tells apart unnamed keys (SQLite) on the same table.
+type KeyColumn struct {
+	ID               int64  `db:"constraint_id"`
+	Name             string `db:"constraint_name"`
+	Schema           string `db:"table_schema"`
+	Table            string `db:"table_name"`
+	Column           string `db:"column_name"`
+	ReferencedSchema string `db:"referenced_table_schema"`
+	ReferencedTable  string `db:"referenced_table_name"`
+	ReferencedColumn string `db:"referenced_column_name"`
+	OnUpdate         string `db:"update_rule"`
+	OnDelete         string `db:"delete_rule"`
+}
+
+// GroupKeys folds key columns, ordered by key and position, into keys.
+func GroupKeys(columns []KeyColumn) []ForeignKey {
+	var keys []ForeignKey
+	for i, column := range columns {
+		if i > 0 {
+			prev := columns[i-1]
+			if prev.ID == column.ID && prev.Name == column.Name && prev.Schema == column.Schema && prev.Table == column.Table {
+				last := &keys[len(keys)-1]
+				last.Columns = append(last.Columns, column.Column)
+				last.ReferencedColumns = append(last.ReferencedColumns, column.ReferencedColumn)
+				continue
+			}
+		}
+		keys = append(keys, ForeignKey{
+			Name:              column.Name,
+			Table:             TableRef{Schema: column.Schema, Name: column.Table},
+			Columns:           []string{column.Column},
+			Referenced:        TableRef{Schema: column.ReferencedSchema, Name: column.ReferencedTable},
+			ReferencedColumns: []string{column.ReferencedColumn},
+			OnUpdate:          column.OnUpdate,
+			OnDelete:          column.OnDelete,
+		})
+	}
+	return keys
+}
+
+// Keys returns the declared keys whose tables and columns are all visible,
+// followed by the keys inferred from the visible columns of tables. A
+// column named <name>_id is taken to reference the id column of a base
+// table called <name> or its plural, preferring one in the same schema;
+// columns already covered by a declared key are skipped.
+func (a AccessRules) Keys(tables []TableRef, columns [][]types.Column, declared []ForeignKey) []ForeignKey {
+	visible := make(map[tableKey]TableRef, len(tables))
+	for _, t := range tables {
+		visible[keyOf(t)] = t
+	}
+
+	var keys []ForeignKey
+	covered := make(map[tableKey]map[string]bool)
+	for _, key := range declared {
+		table, ok1 := visible[keyOf(key.Table)]
+		referenced, ok2 := visible[keyOf(key.Referenced)]
+		if !ok1 || !ok2 || !a.columnsVisible(table, key.Columns) || !a.columnsVisible(referenced, key.ReferencedColumns) {
+			continue
+		}
+		key.Table, key.Referenced = table, referenced
+		keys = append(keys, key)
+
+		if covered[keyOf(table)] == nil {
+			covered[keyOf(table)] = make(map[string]bool)
+		}
+		for _, name := range key.Columns {
+			covered[keyOf(table)][strings.ToLower(name)] = true
+		}
+	}
+
+	// Base tables with an id column, by lower-cased name
+	idColumns := make([]string, len(tables))
+	byName := make(map[string][]int)
+	for i, t := range tables {
+		if t.Type != TypeBaseTable {
+			continue
+		}
+		for _, column := range columns[i] {
+			if strings.EqualFold(column.Name, "id") {
+				idColumns[i] = column.Name
+				name := strings.ToLower(t.Name)
+				byName[name] = append(byName[name], i)
+				break
+			}
+		}
+	}
+
+	for i, t := range tables {
+		for _, column := range columns[i] {
+			name := strings.ToLower(column.Name)
+			base, ok := strings.CutSuffix(name, "_id")
+			if !ok || base == "" || covered[keyOf(t)][name] {
+				continue
+			}
+			target := referencedTable(t, base, tables, byName)
+			if target < 0 || target == i {
+				continue
+			}
+			keys = append(keys, ForeignKey{
+				Table:             t,
+				Columns:           []string{column.Name},
+				Referenced:        tables[target],
+				ReferencedColumns: []string{idColumns[target]},
+				Inferred:          true,
+			})
+		}
+	}
+	return keys
+}
+
+func (a AccessRules) columnsVisible(ref TableRef, columns []string) bool {
+	for _, name := range columns {
+		if !a.ColumnVisible(ref, name) {
+			return false
+		}
+	}
+	return true
+}
+
+// referencedTable returns the index of the table a <base>_id column of from
+// points at, or -1. Outside from's schema the match must be unique.
+func referencedTable(from TableRef, base string, tables []TableRef, byName map[string][]int) int {
+	names := []string{base, base + "s", base + "es"}
+	if stem, ok := strings.CutSuffix(base, "y"); ok {
+		names = append(names, stem+"ies")
+	}
+
+	var candidates []int
+	for _, name := range names {
+		candidates = append(candidates, byName[name]...)
+	}
+	for _, i := range candidates {
+		if tables[i].Schema == from.Schema {
+			return i
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return -1
+}
+
+// Relationships renders the keys involving any of only, or every key when
+// only is empty.
+func Relationships(dialect types.Dialect, keys []ForeignKey, only []TableRef) []types.Relationship {
+	wanted := make(map[tableKey]bool, len(only))
+	for _, ref := range only {
+		wanted[keyOf(ref)] = true
+	}
+
+	relationships := []types.Relationship{}
+	for _, key := range keys {
+		if len(only) == 0 || wanted[keyOf(key.Table)] || wanted[keyOf(key.Referenced)] {
+			relationships = append(relationships, key.Relationship(dialect))
+		}
+	}
+	return relationships
+}
+
+// DescribeRelationships fills in the keys from and to ref.
+func DescribeRelationships(dialect types.Dialect, keys []ForeignKey, ref TableRef, description *types.TableDescription) {
+	for _, key := range keys {
+		if keyOf(key.Table) == keyOf(ref) {
+			description.ForeignKeys = append(description.ForeignKeys, key.Relationship(dialect))
+		}
+		if keyOf(key.Referenced) == keyOf(ref) {
+			description.ReferencedBy = append(description.ReferencedBy, key.Relationship(dialect))
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// ReadRows scans rows one at a time and stops as soon as a limit in opts
+// is reached, so large results are never held in memory in full. cancel
+// must end the context the query runs under; it is called when a limit is
+// reached, since closing rows would otherwise read and discard the rest of
+// the result.
+func ReadRows(rows *sqlx.Rows, opts types.QueryOptions, cancel context.CancelFunc) (*types.QueryResult, error) {
+	result := &types.QueryResult{Rows: []map[string]any{}}
+
+	size := 0
+	for rows.Next() {
+		if opts.MaxRows > 0 && result.RowCount == opts.MaxRows {
+			result.Truncated = true
+			result.Limit = "max_rows"
+			break
+		}
+
+		row := make(map[string]any)
+		if err := rows.MapScan(row); err != nil {
+			return nil, fmt.Errorf("unable to scan row: %w", err)
+		}
+
+		if opts.MaxBytes > 0 {
+			encoded, err := json.Marshal(row)
+			if err != nil {
+				return nil, fmt.Errorf("unable to encode row: %w", err)
+			}
+			size += len(encoded)
+			if size > opts.MaxBytes {
+				result.Truncated = true
+				result.Limit = "max_result_bytes"
+				break
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+		result.RowCount++
+	}
+	if result.Truncated {
+		// The rows read so far are complete; the cancelled query's error is not
+		cancel()
+		return result, nil
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read rows: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+func TestReadRows(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// An endless result, so reading past a limit would never finish. Each
+	// row encodes as {"n":1}, 7 bytes or more
+	const endless = "WITH RECURSIVE r(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM r) SELECT n FROM r"
+
+	tests := []struct {
+		name      string
+		query     string
+		opts      types.QueryOptions
+		rows      int
+		limit     string
+		cancelled bool
+	}{
+		{"max rows", endless, types.QueryOptions{MaxRows: 3}, 3, "max_rows", true},
+		{"max bytes", endless, types.QueryOptions{MaxBytes: 40}, 5, "max_result_bytes", true},
+		{"under limits", "SELECT 1 AS n UNION ALL SELECT 2", types.QueryOptions{MaxRows: 3, MaxBytes: 100}, 2, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rows, err := db.QueryxContext(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+
+			cancelled := false
+			result, err := ReadRows(rows, tt.opts, func() {
+				cancelled = true
+				cancel()
+			})
+			if err != nil {
+				t.Fatalf("ReadRows() error = %v", err)
+			}
+			if result.RowCount != tt.rows || len(result.Rows) != tt.rows {
+				t.Errorf("read %d rows, want %d", result.RowCount, tt.rows)
+			}
+			if result.Truncated != (tt.limit != "") || result.Limit != tt.limit {
+				t.Errorf("truncated = %v by %q, want limit %q", result.Truncated, result.Limit, tt.limit)
+			}
+			if cancelled != tt.cancelled {
+				t.Errorf("cancelled = %v, want %v", cancelled, tt.cancelled)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/guard"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// SampleQuery builds the SELECT used by the sample_table tool for a table
+// that has already been resolved against the catalog.
+type SampleQuery struct {
+	Dialect types.Dialect
+	Table   TableRef
+	// Columns are the table's catalog columns; requested column names are
+	// matched against them and only their catalog spelling is quoted
+	Columns []types.Column
+	// TableSample is an optional TABLESAMPLE clause placed after the table
+	TableSample string
+	// ListColumns selects Columns by name instead of *, so columns hidden
+	// by access rules are not read
+	ListColumns bool
+}
+
+// Build renders the query for opts. opts.Limit must already be positive.
+func (q SampleQuery) Build(opts types.SampleOptions) (string, error) {
+	if opts.Random && opts.OrderBy != "" {
+		return "", fmt.Errorf("random and order_by cannot be combined")
+	}
+
+	selectList := "*"
+	if len(opts.Columns) == 0 && q.ListColumns {
+		if len(q.Columns) == 0 {
+			return "", fmt.Errorf("table %s has no visible columns", q.Table)
+		}
+		quoted := make([]string, 0, len(q.Columns))
+		for _, col := range q.Columns {
+			quoted = append(quoted, QuoteIdent(q.Dialect, col.Name))
+		}
+		selectList = strings.Join(quoted, ", ")
+	} else if len(opts.Columns) > 0 {
+		quoted := make([]string, 0, len(opts.Columns))
+		for _, requested := range opts.Columns {
+			name, err := q.column(requested)
+			if err != nil {
+				return "", err
+			}
+			quoted = append(quoted, QuoteIdent(q.Dialect, name))
+		}
+		selectList = strings.Join(quoted, ", ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELECT %s FROM %s", selectList, q.Table.Quote(q.Dialect))
+	if q.TableSample != "" {
+		b.WriteString(" " + q.TableSample)
+	}
+
+	if where := strings.TrimSpace(opts.Where); where != "" {
+		if err := guard.CheckExpression(q.Dialect, where); err != nil {
+			return "", fmt.Errorf("invalid where clause: %w", err)
+		}
+		// The newline keeps a trailing line comment from swallowing the paren
+		fmt.Fprintf(&b, " WHERE (%s\n)", where)
+	}
+
+	switch {
+	case opts.Random:
+		b.WriteString(" ORDER BY " + randomFunction(q.Dialect))
+	case strings.TrimSpace(opts.OrderBy) != "":
+		orderBy, err := q.orderBy(opts.OrderBy)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" ORDER BY " + orderBy)
+	}
+
+	fmt.Fprintf(&b, " LIMIT %d", opts.Limit)
+	return b.String(), nil
+}
+
+// orderBy parses "col [ASC|DESC], ..." into a quoted ORDER BY list.
+func (q SampleQuery) orderBy(raw string) (string, error) {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		fields := strings.Fields(item)
+		if len(fields) == 0 || len(fields) > 2 {
+			return "", fmt.Errorf("invalid order_by item %q, expected \"column [ASC|DESC]\"", strings.TrimSpace(item))
+		}
+
+		name, err := q.column(fields[0])
+		if err != nil {
+			return "", err
+		}
+
+		direction := "ASC"
+		if len(fields) == 2 {
+			direction = strings.ToUpper(fields[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", fmt.Errorf("invalid order_by direction %q, expected ASC or DESC", fields[1])
+			}
+		}
+		items = append(items, QuoteIdent(q.Dialect, name)+" "+direction)
+	}
+	return strings.Join(items, ", "), nil
+}
+
+// column returns the catalog spelling of a requested column name.
+func (q SampleQuery) column(requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	var folded []string
+	for _, c := range q.Columns {
+		if c.Name == requested {
+			return c.Name, nil
+		}
+		if strings.EqualFold(c.Name, requested) {
+			folded = append(folded, c.Name)
+		}
+	}
+	if len(folded) == 1 {
+		return folded[0], nil
+	}
+	return "", fmt.Errorf("column %s not found in table %s", requested, q.Table)
+}
+
+func randomFunction(dialect types.Dialect) string {
+	if dialect == types.DialectMySQL {
+		return "RAND()"
+	}
+	return "RANDOM()"
+}
@@ -0,0 +1,86 @@
+package catalog
+
+import (
+	"strings"
+	"time"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// Options configures how a connector reads its catalog.
+type Options struct {
+	Schemas SchemaFilter
+	// Access hides tables and columns within the visible schemas
+	Access AccessRules
+	// CacheTTL is how long catalog reads are cached; zero or less disables
+	// the cache
+	CacheTTL time.Duration
+}
+
+// SchemaFilter limits which schemas (PostgreSQL schemas, MySQL databases or
+// SQLite attached databases) are visible. Entries may use * and ? wildcards.
+type SchemaFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// systemSchemas are hidden unless a schema filter includes them by name.
+var systemSchemas = map[types.Dialect][]string{
+	types.DialectPostgres: {"pg_catalog", "information_schema", "pg_toast", "pg_toast_temp_*", "pg_temp_*"},
+	types.DialectMySQL:    {"information_schema", "mysql", "performance_schema", "sys"},
+	types.DialectSQLite:   {"temp"},
+}
+
+// IsSystemSchema reports whether schema holds the database's own catalog.
+func IsSystemSchema(dialect types.Dialect, schema string) bool {
+	return matchesAny(systemSchemas[dialect], schema)
+}
+
+// Allows reports whether schema is visible. Exclusions win over inclusions;
+// with no include list every non-system schema is visible.
+func (f SchemaFilter) Allows(dialect types.Dialect, schema string) bool {
+	if matchesAny(f.Exclude, schema) {
+		return false
+	}
+	if len(f.Include) > 0 {
+		return matchesAny(f.Include, schema)
+	}
+	return !IsSystemSchema(dialect, schema)
+}
+
+// HasInclude reports whether an explicit include list was configured.
+func (f SchemaFilter) HasInclude() bool {
+	return len(f.Include) > 0
+}
+
+func matchesAny(patterns []string, schema string) bool {
+	for _, p := range patterns {
+		if strings.ContainsAny(p, "*?") {
+			if globMatch(p, schema) {
+				return true
+			}
+		} else if strings.EqualFold(p, schema) {
+			return true
+		}
+	}
+	return false
+}
+
+// Schemas builds the list_schemas summary from the schema names the
+// database reports and the (already filtered) tables in them.
+func Schemas(names []string, tables []TableRef, defaultSchema string) []types.Schema {
+	counts := make(map[string]int)
+	for _, t := range tables {
+		counts[t.Schema]++
+	}
+
+	schemas := make([]types.Schema, 0, len(names))
+	for _, name := range names {
+		schemas = append(schemas, types.Schema{
+			Name:    name,
+			Tables:  counts[name],
+			Default: name == defaultSchema,
+		})
+	}
+	return schemas
+}
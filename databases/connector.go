@@ -3,7 +3,9 @@ package databases
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/melkeydev/mcp-database/databases/catalog"
 	"github.com/melkeydev/mcp-database/databases/mysql"
 	"github.com/melkeydev/mcp-database/databases/postgres"
 	"github.com/melkeydev/mcp-database/databases/sqlite"
@@ -12,22 +14,35 @@ import (
 
 type DatabaseConnector interface {
 	Ping(ctx context.Context) error
-	Scan(ctx context.Context, tableList []string) ([]types.Table, error)
-	Query(ctx context.Context, sql string) ([]map[string]any, error)
-	Sample(ctx context.Context, table string, limit int) ([]map[string]any, error)
-	DescribeTable(ctx context.Context, table string) (*types.TableDescription, error)
+	Dialect() types.Dialect
+	Scan(ctx context.Context, tableList []string) (*types.ScanResult, error)
+	ListTables(ctx context.Context) ([]catalog.TableRef, error)
+	RefreshSchema(ctx context.Context, tables []string) ([]catalog.TableRef, error)
+	ListSchemas(ctx context.Context) ([]types.Schema, error)
+	CheckAccess(ctx context.Context, sql string) error
+	Query(ctx context.Context, sql string, opts types.QueryOptions) (*types.QueryResult, error)
+	Explain(ctx context.Context, sql string, timeout time.Duration) (*types.QueryPlan, error)
+	// Sample and DescribeTable also return the table or view table resolves to
+	Sample(ctx context.Context, table string, opts types.SampleOptions) (catalog.TableRef, []map[string]any, error)
+	DescribeTable(ctx context.Context, table string, opts types.DescribeOptions) (catalog.TableRef, *types.TableDescription, error)
+	Relationships(ctx context.Context, tables []string) ([]types.Relationship, error)
+	JoinPaths(ctx context.Context, tables []string, opts types.JoinPathOptions) ([]types.JoinPath, error)
+	SchemaGraph(ctx context.Context, opts types.DiagramOptions) (*types.SchemaGraph, error)
+	ListObjects(ctx context.Context, kinds []string) ([]types.SchemaObject, error)
+	DescribeObject(ctx context.Context, name, kind string) ([]types.SchemaObject, error)
 	Close() error
-	// ListTables(ctx context.Context) ([]string, error)
 }
 
-func NewConnector(dbType, connectionString string) (DatabaseConnector, error) {
+// NewConnector opens a connector and pings the database, giving up when ctx
+// ends.
+func NewConnector(ctx context.Context, dbType, connectionString string, opts catalog.Options) (DatabaseConnector, error) {
 	switch dbType {
 	case "postgres", "postgresql":
-		return postgres.NewPostgresConnector(connectionString)
+		return postgres.NewPostgresConnector(ctx, connectionString, opts)
 	case "mysql":
-		return mysql.NewMySQLConnector(connectionString)
+		return mysql.NewMySQLConnector(ctx, connectionString, opts)
 	case "sqlite":
-		return sqlite.NewSQLiteConnector(connectionString)
+		return sqlite.NewSQLiteConnector(ctx, connectionString, opts)
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", dbType)
 	}
@@ -0,0 +1,221 @@
+package databases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/databases/catalog"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// pingTimeout bounds each reachability check made by Status.
+const pingTimeout = 5 * time.Second
+
+// Connection is an open connector together with the name it is configured
+// under.
+type Connection struct {
+	DatabaseConnector
+	Name string
+}
+
+// Manager owns the named connections from the config. Connectors are opened
+// on first use and kept open until Close; a failed open is retried on the
+// next call.
+type Manager struct {
+	defaultName string
+	entries     map[string]*managedConnection
+}
+
+type managedConnection struct {
+	mu        sync.Mutex
+	cfg       config.DatabaseConfig
+	connector DatabaseConnector
+	// opening is closed when the open in progress, if any, finishes
+	opening chan struct{}
+}
+
+// NewManager creates a manager for the databases in cfg. No connection is
+// opened until it is requested.
+func NewManager(cfg *config.Config) *Manager {
+	m := &Manager{
+		defaultName: cfg.DefaultDatabase,
+		entries:     make(map[string]*managedConnection, len(cfg.Databases)),
+	}
+	for name, dbCfg := range cfg.Databases {
+		m.entries[name] = &managedConnection{cfg: dbCfg}
+	}
+	return m
+}
+
+// Names returns the configured database names in sorted order.
+func (m *Manager) Names() []string {
+	names := make([]string, 0, len(m.entries))
+	for name := range m.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the name used when a tool call does not pick a database.
+func (m *Manager) Default() string {
+	return m.defaultName
+}
+
+// Get returns the named connection, opening it if needed. An empty name
+// selects the default database. Opening gives up when ctx ends.
+func (m *Manager) Get(ctx context.Context, name string) (*Connection, error) {
+	if name == "" {
+		name = m.defaultName
+	}
+
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown database %q, configured databases: %s", name, strings.Join(m.Names(), ", "))
+	}
+
+	connector, err := entry.open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database %s: %w", name, err)
+	}
+
+	return &Connection{DatabaseConnector: connector, Name: name}, nil
+}
+
+// Status opens and pings every configured database.
+func (m *Manager) Status(ctx context.Context) []types.DatabaseStatus {
+	names := m.Names()
+	statuses := make([]types.DatabaseStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		statuses[i] = types.DatabaseStatus{
+			Name:    name,
+			Dialect: DialectOf(m.entries[name].cfg.DBType),
+			Default: name == m.defaultName,
+		}
+
+		wg.Add(1)
+		go func(status *types.DatabaseStatus) {
+			defer wg.Done()
+
+			pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+			defer cancel()
+
+			conn, err := m.Get(pingCtx, status.Name)
+			if err == nil {
+				err = conn.Ping(pingCtx)
+			}
+			if err != nil {
+				status.Error = err.Error()
+				return
+			}
+			status.Reachable = true
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// Close closes every connector that has been opened.
+func (m *Manager) Close() error {
+	var errs []string
+	for name, entry := range m.entries {
+		entry.mu.Lock()
+		if entry.connector != nil {
+			if err := entry.connector.Close(); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			}
+			entry.connector = nil
+		}
+		entry.mu.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close databases: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// open returns the connector, opening it if needed. Only one caller dials
+// at a time, without holding the lock; the others wait for it until their
+// ctx ends and try again themselves if it failed.
+func (e *managedConnection) open(ctx context.Context) (DatabaseConnector, error) {
+	for {
+		e.mu.Lock()
+		if e.connector != nil {
+			connector := e.connector
+			e.mu.Unlock()
+			return connector, nil
+		}
+
+		if e.opening == nil {
+			done := make(chan struct{})
+			e.opening = done
+			e.mu.Unlock()
+
+			connector, err := e.dial(ctx)
+
+			e.mu.Lock()
+			e.opening = nil
+			if err == nil {
+				e.connector = connector
+			}
+			e.mu.Unlock()
+			close(done)
+			return connector, err
+		}
+
+		opening := e.opening
+		e.mu.Unlock()
+		select {
+		case <-opening:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// dial creates the connector from the config.
+func (e *managedConnection) dial(ctx context.Context) (DatabaseConnector, error) {
+	connStr, err := e.cfg.GetConnectionString()
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := catalog.NewAccessRules(e.cfg.Tables.Include, e.cfg.Tables.Exclude, e.cfg.Columns.Include, e.cfg.Columns.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid access rules: %w", err)
+	}
+
+	opts := catalog.Options{
+		Schemas: catalog.SchemaFilter{
+			Include: e.cfg.Schemas.Include,
+			Exclude: e.cfg.Schemas.Exclude,
+		},
+		Access:   access,
+		CacheTTL: e.cfg.SchemaCacheTTL,
+	}
+
+	return NewConnector(ctx, e.cfg.DBType, connStr, opts)
+}
+
+// DialectOf maps a configured database type onto its SQL dialect.
+func DialectOf(dbType string) types.Dialect {
+	switch dbType {
+	case "postgres", "postgresql":
+		return types.DialectPostgres
+	case "mysql":
+		return types.DialectMySQL
+	case "sqlite":
+		return types.DialectSQLite
+	default:
+		return types.Dialect(dbType)
+	}
+}
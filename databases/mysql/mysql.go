@@ -3,19 +3,24 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/melkeydev/mcp-database/databases/catalog"
 	"github.com/melkeydev/mcp-database/types"
 )
 
 type MySQLConnector struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	opts  catalog.Options
+	cache *catalog.Cache
 }
 
-func NewMySQLConnector(connectionString string) (*MySQLConnector, error) {
+func NewMySQLConnector(ctx context.Context, connectionString string, opts catalog.Options) (*MySQLConnector, error) {
 	_, err := mysql.ParseDSN(connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse connection string: %w", err)
@@ -28,10 +33,12 @@ func NewMySQLConnector(connectionString string) (*MySQLConnector, error) {
 	}
 
 	connector := &MySQLConnector{
-		db: db,
+		db:    db,
+		opts:  opts,
+		cache: catalog.NewCache(opts.CacheTTL),
 	}
 
-	if err := connector.Ping(context.Background()); err != nil {
+	if err := connector.Ping(ctx); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -43,8 +50,12 @@ func (c *MySQLConnector) Ping(ctx context.Context) error {
 	return c.db.PingContext(ctx)
 }
 
+func (c *MySQLConnector) Dialect() types.Dialect {
+	return types.DialectMySQL
+}
+
 // Discover
-func (c *MySQLConnector) Scan(ctx context.Context, tablesList []string) ([]types.Table, error) {
+func (c *MySQLConnector) Scan(ctx context.Context, tablesList []string) (*types.ScanResult, error) {
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
@@ -53,67 +64,64 @@ func (c *MySQLConnector) Scan(ctx context.Context, tablesList []string) ([]types
 	}
 	defer tx.Commit()
 
-	var query string
-	var args []interface{}
+	all, err := c.listTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
 
+	matched := catalog.MatchResult{Tables: all}
 	if len(tablesList) > 0 {
-		// Query specific tables
-		placeholders := make([]string, len(tablesList))
-		args = make([]interface{}, len(tablesList))
-
-		for i, table := range tablesList {
-			placeholders[i] = "?"
-			args[i] = table
+		currentDatabase, err := c.currentDatabase(ctx, tx)
+		if err != nil {
+			return nil, err
 		}
-
-		query = fmt.Sprintf(`
-			SELECT table_name, table_schema
-			FROM information_schema.tables 
-			WHERE table_type = 'BASE TABLE'
-			AND table_schema = DATABASE()
-			AND table_name IN (%s)
-		`, strings.Join(placeholders, ","))
-
-	} else {
-		// Query all tables in the current database
-		query = `
-			SELECT table_name, table_schema
-			FROM information_schema.tables 
-			WHERE table_type = 'BASE TABLE'
-			AND table_schema = DATABASE()
-		`
+		matched = catalog.Match(types.DialectMySQL, tablesList, all, currentDatabase)
 	}
 
-	rows, err := tx.QueryContext(ctx, query, args...)
+	result := &types.ScanResult{
+		Tables:      []types.Table{},
+		NotFound:    matched.NotFound,
+		Suggestions: matched.Suggestions,
+	}
+	columns, err := c.loadTableColumns(ctx, tx, matched.Tables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
+		return nil, fmt.Errorf("failed to load columns: %w", err)
 	}
-	defer rows.Close()
-
-	var tables []types.Table
-	for rows.Next() {
-		var tableName, tableSchema string
-		if err := rows.Scan(&tableName, &tableSchema); err != nil {
-			return nil, fmt.Errorf("failed to scan table: %w", err)
-		}
-
-		columns, err := c.loadColumns(ctx, tx, tableName, tableSchema)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load columns for table %s: %w", tableName, err)
-		}
-
-		tables = append(tables, types.Table{
-			Name:    tableName,
-			Columns: columns,
+	for i, ref := range matched.Tables {
+		result.Tables = append(result.Tables, types.Table{
+			Name:    ref.Quote(types.DialectMySQL),
+			Kind:    ref.Kind(),
+			Columns: columns[i],
 		})
 	}
 
-	return tables, nil
+	return result, nil
 }
 
 // Query
-func (c *MySQLConnector) Query(ctx context.Context, sqlQuery string) ([]map[string]any, error) {
-	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
+func (c *MySQLConnector) Query(ctx context.Context, sqlQuery string, opts types.QueryOptions) (*types.QueryResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// max_execution_time is a session setting, so it is set and reset on a
+	// connection of our own rather than whichever one the pool hands out
+	conn, err := c.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if opts.Timeout > 0 {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET SESSION max_execution_time = %d", opts.Timeout.Milliseconds())); err != nil {
+			return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+		defer resetTimeout(conn)
+	}
+
+	tx, err := conn.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
 	if err != nil {
@@ -121,32 +129,122 @@ func (c *MySQLConnector) Query(ctx context.Context, sqlQuery string) ([]map[stri
 	}
 	defer tx.Commit()
 
-	rows, err := tx.QueryxContext(ctx, sqlQuery)
+	// The driver only drops the connection when ctx ends; the statement
+	// keeps running on the server until it is killed. ReadRows cancels
+	// queryCtx once a limit is reached, which kills the statement too.
+	var connectionID int64
+	if err := tx.GetContext(ctx, &connectionID, "SELECT CONNECTION_ID()"); err != nil {
+		return nil, fmt.Errorf("failed to get connection id: %w", err)
+	}
+	queryCtx, cancelQuery := context.WithCancel(ctx)
+	defer cancelQuery()
+	killed := make(chan struct{})
+	stop := context.AfterFunc(queryCtx, func() {
+		defer close(killed)
+		c.killQuery(connectionID)
+	})
+	defer func() {
+		// Wait for a started kill so it cannot hit the connection's next user
+		if !stop() {
+			<-killed
+		}
+	}()
+
+	rows, err := tx.QueryxContext(queryCtx, sqlQuery)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query db: %w", err)
 	}
 	defer rows.Close()
 
-	var results []map[string]any
-	for rows.Next() {
-		row := make(map[string]any)
-		if err := rows.MapScan(row); err != nil {
-			return nil, fmt.Errorf("unable to scan row: %w", err)
+	return catalog.ReadRows(rows, opts, cancelQuery)
+}
+
+// Explain returns the planner's plan for a query without running it
+func (c *MySQLConnector) Explain(ctx context.Context, sqlQuery string, timeout time.Duration) (*types.QueryPlan, error) {
+	result, err := c.Query(ctx, "EXPLAIN FORMAT=JSON "+sqlQuery, types.QueryOptions{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	text, err := catalog.PlanText(result)
+	if err != nil {
+		return nil, err
+	}
+	return parsePlan(text)
+}
+
+// Sample
+func (c *MySQLConnector) Sample(ctx context.Context, table string, opts types.SampleOptions) (catalog.TableRef, []map[string]any, error) {
+	ref, err := c.resolveTable(ctx, c.db, table)
+	if err != nil {
+		return catalog.TableRef{}, nil, err
+	}
+
+	rows, err := c.sampleTable(ctx, ref, opts)
+	return ref, rows, err
+}
+
+func (c *MySQLConnector) sampleTable(ctx context.Context, ref catalog.TableRef, opts types.SampleOptions) ([]map[string]any, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	columns, err := c.loadColumns(ctx, c.db, ref.Name, ref.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load columns: %w", err)
+	}
+
+	if opts.Where != "" {
+		check := fmt.Sprintf("SELECT 1 FROM %s WHERE (%s\n)", ref.Quote(types.DialectMySQL), opts.Where)
+		if err := c.CheckAccess(ctx, check); err != nil {
+			return nil, fmt.Errorf("invalid where clause: %w", err)
 		}
-		results = append(results, row)
 	}
 
-	return results, nil
+	sample := catalog.SampleQuery{
+		Dialect:     types.DialectMySQL,
+		Table:       ref,
+		Columns:     columns,
+		ListColumns: !c.opts.Access.IsZero(),
+	}
+
+	query, err := sample.Build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnQuery != nil {
+		opts.OnQuery(query)
+	}
+
+	result, err := c.Query(ctx, query, types.QueryOptions{
+		MaxRows:  opts.Limit,
+		MaxBytes: opts.MaxBytes,
+		Timeout:  opts.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
 }
 
-// Sample
-func (c *MySQLConnector) Sample(ctx context.Context, table string, limit int) ([]map[string]any, error) {
-	if limit <= 0 {
-		limit = 10
+// resetTimeout restores max_execution_time before conn goes back to the
+// pool. When that fails, e.g. because a cancelled query left the
+// connection closed, the connection is discarded instead so the timeout
+// cannot apply to its next user.
+func resetTimeout(conn *sqlx.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, "SET SESSION max_execution_time = DEFAULT"); err != nil {
+		conn.Raw(func(any) error { return driver.ErrBadConn })
 	}
+}
 
-	query := fmt.Sprintf("SELECT * FROM `%s` LIMIT %d", table, limit)
-	return c.Query(ctx, query)
+// killQuery stops the statement running on another connection.
+func (c *MySQLConnector) killQuery(connectionID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID))
 }
 
 func (c *MySQLConnector) Close() error {
@@ -156,99 +254,562 @@ func (c *MySQLConnector) Close() error {
 	return nil
 }
 
-func (c *MySQLConnector) loadColumns(ctx context.Context, tx *sqlx.Tx, tableName, tableSchema string) ([]types.Column, error) {
-	query := `
-		SELECT column_name, data_type, is_nullable
-		FROM information_schema.columns
-		WHERE table_name = ? AND table_schema = ?
-		ORDER BY ordinal_position
-	`
+// listTables returns every table and view in the visible databases.
+func (c *MySQLConnector) listTables(ctx context.Context, q sqlx.QueryerContext) ([]catalog.TableRef, error) {
+	return c.cache.Tables(func() ([]catalog.TableRef, error) {
+		currentDatabase, err := c.currentDatabase(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		var all []catalog.TableRef
+		err = sqlx.SelectContext(ctx, q, &all, `
+			SELECT table_schema AS table_schema, table_name AS table_name, table_type AS table_type
+			FROM information_schema.tables
+			ORDER BY table_schema, table_name
+		`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		var tables []catalog.TableRef
+		for _, ref := range all {
+			if c.databaseVisible(ref.Schema, currentDatabase) && c.opts.Access.TableVisible(ref) {
+				tables = append(tables, ref)
+			}
+		}
+		return tables, nil
+	})
+}
+
+// databaseVisible applies the schema filter to MySQL databases. Without an
+// include list only the database from the connection string is visible.
+func (c *MySQLConnector) databaseVisible(name, currentDatabase string) bool {
+	if !c.opts.Schemas.HasInclude() && name != currentDatabase {
+		return false
+	}
+	return c.opts.Schemas.Allows(types.DialectMySQL, name)
+}
+
+// RefreshSchema drops cached catalog entries for the named tables, or for
+// every table when none are named
+func (c *MySQLConnector) RefreshSchema(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	return c.cache.Refresh(tables, func(name string) (catalog.TableRef, error) {
+		return c.resolveTable(ctx, c.db, name)
+	})
+}
+
+// CheckAccess rejects a query that references a hidden table or column
+func (c *MySQLConnector) CheckAccess(ctx context.Context, query string) error {
+	return c.opts.Access.CheckQuery(types.DialectMySQL, query, catalog.AccessLookup{
+		Resolve: func(name string) (catalog.TableRef, error) {
+			return c.resolveTable(ctx, c.db, name)
+		},
+		Columns: func(ref catalog.TableRef) ([]types.Column, error) {
+			columns, err := c.loadAllColumns(ctx, c.db, []catalog.TableRef{ref})
+			if err != nil {
+				return nil, err
+			}
+			return columns[0], nil
+		},
+	})
+}
+
+// ListTables returns every visible table and view
+func (c *MySQLConnector) ListTables(ctx context.Context) ([]catalog.TableRef, error) {
+	return c.listTables(ctx, c.db)
+}
+
+// ListSchemas returns the databases allowed by the schema filter
+func (c *MySQLConnector) ListSchemas(ctx context.Context) ([]types.Schema, error) {
+	currentDatabase, err := c.currentDatabase(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := c.listDatabases(ctx, currentDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.Schemas(names, tables, currentDatabase), nil
+}
+
+// listDatabases returns the names of the visible databases.
+func (c *MySQLConnector) listDatabases(ctx context.Context, currentDatabase string) ([]string, error) {
+	return c.cache.Schemas(func() ([]string, error) {
+		var all []string
+		err := c.db.SelectContext(ctx, &all, `
+			SELECT schema_name AS schema_name
+			FROM information_schema.schemata
+			ORDER BY schema_name`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+
+		var names []string
+		for _, name := range all {
+			if c.databaseVisible(name, currentDatabase) {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	})
+}
+
+// resolveTable maps a caller supplied table name onto a catalog entry,
+// defaulting unqualified names to the current database.
+func (c *MySQLConnector) resolveTable(ctx context.Context, q sqlx.QueryerContext, table string) (catalog.TableRef, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return catalog.TableRef{}, err
+	}
+
+	currentDatabase, err := c.currentDatabase(ctx, q)
+	if err != nil {
+		return catalog.TableRef{}, err
+	}
+
+	return catalog.Resolve(types.DialectMySQL, table, tables, currentDatabase)
+}
+
+// currentDatabase returns the database selected by the connection string.
+func (c *MySQLConnector) currentDatabase(ctx context.Context, q sqlx.QueryerContext) (string, error) {
+	return c.cache.DefaultSchema(func() (string, error) {
+		var currentDatabase string
+		if err := sqlx.GetContext(ctx, q, &currentDatabase, "SELECT DATABASE()"); err != nil {
+			return "", fmt.Errorf("failed to get database name: %w", err)
+		}
+		return currentDatabase, nil
+	})
+}
+
+// loadColumns returns the columns of a single table.
+func (c *MySQLConnector) loadColumns(ctx context.Context, q sqlx.QueryerContext, tableName, tableSchema string) ([]types.Column, error) {
+	columns, err := c.loadTableColumns(ctx, q, []catalog.TableRef{{Schema: tableSchema, Name: tableName}})
+	if err != nil {
+		return nil, err
+	}
+	return columns[0], nil
+}
+
+// columnBatchSize bounds how many tables one column query names, keeping
+// the statement well under the placeholder limit.
+const columnBatchSize = 1000
+
+// loadTableColumns returns the visible columns of every table in refs.
+func (c *MySQLConnector) loadTableColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	columns, err := c.loadAllColumns(ctx, q, refs)
+	if err != nil {
+		return nil, err
+	}
+	for i, ref := range refs {
+		columns[i] = c.opts.Access.VisibleColumns(ref, columns[i])
+	}
+	return columns, nil
+}
+
+// loadAllColumns returns the columns of every table in refs, hidden or not,
+// reading the uncached ones with one catalog query per columnBatchSize
+// tables.
+func (c *MySQLConnector) loadAllColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	return c.cache.Columns(refs, func(missing []catalog.TableRef, add func(schema, name string, column types.Column)) error {
+		for start := 0; start < len(missing); start += columnBatchSize {
+			batch := missing[start:min(start+columnBatchSize, len(missing))]
+
+			args := make([]any, 0, 2*len(batch))
+			for _, ref := range batch {
+				args = append(args, ref.Schema, ref.Name)
+			}
+			tuples := strings.TrimSuffix(strings.Repeat("(?, ?), ", len(batch)), ", ")
+
+			query := fmt.Sprintf(`
+				SELECT table_schema, table_name, column_name, data_type, is_nullable
+				FROM information_schema.columns
+				WHERE (table_schema, table_name) IN (%s)
+				ORDER BY table_schema, table_name, ordinal_position
+			`, tuples)
+
+			if err := c.scanColumns(ctx, q, query, args, add); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	rows, err := tx.QueryContext(ctx, query, tableName, tableSchema)
+func (c *MySQLConnector) scanColumns(ctx context.Context, q sqlx.QueryerContext, query string, args []any, add func(schema, name string, column types.Column)) error {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query columns: %w", err)
+		return fmt.Errorf("failed to query columns: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []types.Column
 	for rows.Next() {
-		var name, dataType, isNullable string
-		if err := rows.Scan(&name, &dataType, &isNullable); err != nil {
-			return nil, fmt.Errorf("failed to scan column: %w", err)
+		var schema, table, name, dataType, isNullable string
+		if err := rows.Scan(&schema, &table, &name, &dataType, &isNullable); err != nil {
+			return fmt.Errorf("failed to scan column: %w", err)
 		}
 
-		columns = append(columns, types.Column{
+		add(schema, table, types.Column{
 			Name:     name,
 			Type:     dataType,
 			Nullable: isNullable == "YES",
 		})
 	}
 
-	return columns, nil
+	return rows.Err()
 }
 
-// DescribeTable returns detailed information about a specific table
-func (c *MySQLConnector) DescribeTable(ctx context.Context, table string) (*types.TableDescription, error) {
-	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
-		ReadOnly: true,
+// Relationships returns the declared and inferred relationships involving
+// the named tables, or every relationship when none are named.
+func (c *MySQLConnector) Relationships(ctx context.Context, tables []string) ([]types.Relationship, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Relationships(types.DialectMySQL, keys, refs), nil
+}
+
+// JoinPaths suggests how to join the named tables through their
+// relationships, best first.
+func (c *MySQLConnector) JoinPaths(ctx context.Context, tables []string, opts types.JoinPathOptions) ([]types.JoinPath, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.JoinPaths(types.DialectMySQL, keys, refs, opts)
+}
+
+// SchemaGraph returns the tables selected by opts with their columns,
+// primary keys and relationships, for drawing a diagram.
+func (c *MySQLConnector) SchemaGraph(ctx context.Context, opts types.DiagramOptions) (*types.SchemaGraph, error) {
+	only, err := c.resolveTables(ctx, opts.Tables)
+	if err != nil {
+		return nil, err
+	}
+	var focus *catalog.TableRef
+	if opts.Focus != "" {
+		ref, err := c.resolveTable(ctx, c.db, opts.Focus)
+		if err != nil {
+			return nil, err
+		}
+		focus = &ref
+	}
+
+	all, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := catalog.DiagramTables(all, keys, only, focus, opts.Hops, opts.IncludeInferred)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.loadTableColumns(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, err := c.loadPrimaryKeys(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.SchemaGraph(types.DialectMySQL, tables, columns, primaryKeys, keys, opts.IncludeInferred), nil
+}
+
+// loadPrimaryKeys returns the primary key columns of the tables in refs,
+// keyed by schema and name.
+func (c *MySQLConnector) loadPrimaryKeys(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) (map[catalog.TableRef][]string, error) {
+	primaryKeys := make(map[catalog.TableRef][]string)
+	if len(refs) == 0 {
+		return primaryKeys, nil
+	}
+
+	args := make([]any, 0, 2*len(refs))
+	for _, ref := range refs {
+		args = append(args, ref.Schema, ref.Name)
+	}
+	tuples := strings.TrimSuffix(strings.Repeat("(?, ?), ", len(refs)), ", ")
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`
+		SELECT table_schema, table_name, column_name
+		FROM information_schema.key_column_usage
+		WHERE constraint_name = 'PRIMARY'
+		AND (table_schema, table_name) IN (%s)
+		ORDER BY table_schema, table_name, ordinal_position
+	`, tuples), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query primary keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref catalog.TableRef
+		var column string
+		if err := rows.Scan(&ref.Schema, &ref.Name, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+		}
+		primaryKeys[ref] = append(primaryKeys[ref], column)
+	}
+	return primaryKeys, rows.Err()
+}
+
+// ListObjects returns the views, materialized views, functions,
+// procedures and triggers of the given kinds, or of every kind when none
+// are given, without their definitions.
+func (c *MySQLConnector) ListObjects(ctx context.Context, kinds []string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.FilterObjects(objects, kinds)
+}
+
+// DescribeObject returns the objects called name, optionally only those of
+// kind, with their definitions.
+func (c *MySQLConnector) DescribeObject(ctx context.Context, name, kind string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	currentDatabase, err := c.currentDatabase(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.FindObjects(types.DialectMySQL, objects, name, kind, currentDatabase)
+}
+
+// objectQueries read the views, routines and triggers of every database.
+// They run separately because information_schema columns and string
+// literals may not share a collation in a UNION.
+var objectQueries = []string{`
+	SELECT
+		TABLE_SCHEMA AS object_schema,
+		TABLE_NAME AS object_name,
+		'view' AS object_kind,
+		'' AS table_name,
+		'' AS signature,
+		'' AS language,
+		COALESCE(VIEW_DEFINITION, '') AS definition
+	FROM information_schema.VIEWS
+	ORDER BY TABLE_SCHEMA, TABLE_NAME
+`, `
+	SELECT
+		r.ROUTINE_SCHEMA AS object_schema,
+		r.ROUTINE_NAME AS object_name,
+		LOWER(r.ROUTINE_TYPE) AS object_kind,
+		'' AS table_name,
+		CONCAT('(', COALESCE((
+			SELECT GROUP_CONCAT(CONCAT_WS(' ', p.PARAMETER_MODE, p.PARAMETER_NAME, p.DTD_IDENTIFIER) ORDER BY p.ORDINAL_POSITION SEPARATOR ', ')
+			FROM information_schema.PARAMETERS p
+			WHERE p.SPECIFIC_SCHEMA = r.ROUTINE_SCHEMA
+			AND p.SPECIFIC_NAME = r.SPECIFIC_NAME
+			AND p.ORDINAL_POSITION > 0
+		), ''), ')', IF(r.ROUTINE_TYPE = 'FUNCTION', CONCAT(' RETURNS ', r.DTD_IDENTIFIER), '')) AS signature,
+		r.ROUTINE_BODY AS language,
+		COALESCE(r.ROUTINE_DEFINITION, '') AS definition
+	FROM information_schema.ROUTINES r
+	ORDER BY r.ROUTINE_SCHEMA, r.ROUTINE_NAME
+`, `
+	SELECT
+		TRIGGER_SCHEMA AS object_schema,
+		TRIGGER_NAME AS object_name,
+		'trigger' AS object_kind,
+		EVENT_OBJECT_TABLE AS table_name,
+		'' AS signature,
+		'' AS language,
+		CONCAT('CREATE TRIGGER ', TRIGGER_NAME, ' ', ACTION_TIMING, ' ', EVENT_MANIPULATION,
+			' ON ', EVENT_OBJECT_TABLE, ' FOR EACH ROW ', ACTION_STATEMENT) AS definition
+	FROM information_schema.TRIGGERS
+	ORDER BY TRIGGER_SCHEMA, TRIGGER_NAME
+`}
+
+// loadObjects returns the views, functions, procedures and triggers of the
+// visible databases with their definitions. Definitions are empty where
+// the user lacks the privilege to see them.
+func (c *MySQLConnector) loadObjects(ctx context.Context, q sqlx.QueryerContext) ([]types.SchemaObject, error) {
+	currentDatabase, err := c.currentDatabase(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []types.SchemaObject
+	for _, query := range objectQueries {
+		var found []types.SchemaObject
+		if err := sqlx.SelectContext(ctx, q, &found, query); err != nil {
+			return nil, fmt.Errorf("failed to list schema objects: %w", err)
+		}
+		for _, object := range found {
+			if c.databaseVisible(object.Schema, currentDatabase) {
+				objects = append(objects, object)
+			}
+		}
+	}
+	return catalog.VisibleObjects(objects, tables), nil
+}
+
+// resolveTables maps each of the caller supplied table names onto a
+// catalog entry.
+func (c *MySQLConnector) resolveTables(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	refs := make([]catalog.TableRef, 0, len(tables))
+	for _, name := range tables {
+		ref, err := c.resolveTable(ctx, c.db, name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// loadKeys returns the visible declared foreign keys followed by the
+// inferred ones.
+func (c *MySQLConnector) loadKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := c.cache.ForeignKeys(func() ([]catalog.ForeignKey, error) {
+		return c.loadForeignKeys(ctx, q)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
 	}
-	defer tx.Commit()
 
-	// Check if table exists
-	var exists bool
-	err = tx.GetContext(ctx, &exists, `
-		SELECT EXISTS (
-			SELECT 1 FROM information_schema.tables 
-			WHERE table_schema = DATABASE() AND table_name = ?
-		)`, table)
+	columns, err := c.loadTableColumns(ctx, q, tables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check table existence: %w", err)
+		return nil, err
 	}
-	if !exists {
-		return nil, fmt.Errorf("table %s not found", table)
+	return c.opts.Access.Keys(tables, columns, declared), nil
+}
+
+// loadForeignKeys reads every foreign key from
+// information_schema.referential_constraints and key_column_usage.
+func (c *MySQLConnector) loadForeignKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	var columns []catalog.KeyColumn
+	err := sqlx.SelectContext(ctx, q, &columns, `
+		SELECT
+			0 AS constraint_id,
+			kcu.CONSTRAINT_NAME AS constraint_name,
+			kcu.TABLE_SCHEMA AS table_schema,
+			kcu.TABLE_NAME AS table_name,
+			kcu.COLUMN_NAME AS column_name,
+			kcu.REFERENCED_TABLE_SCHEMA AS referenced_table_schema,
+			kcu.REFERENCED_TABLE_NAME AS referenced_table_name,
+			kcu.REFERENCED_COLUMN_NAME AS referenced_column_name,
+			rc.UPDATE_RULE AS update_rule,
+			rc.DELETE_RULE AS delete_rule
+		FROM information_schema.REFERENTIAL_CONSTRAINTS rc
+		JOIN information_schema.KEY_COLUMN_USAGE kcu
+			ON kcu.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+			AND kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+			AND kcu.TABLE_NAME = rc.TABLE_NAME
+		WHERE kcu.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY kcu.TABLE_SCHEMA, kcu.TABLE_NAME, kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	return catalog.GroupKeys(columns), nil
+}
+
+// countRows counts the rows of ref, giving up after timeout. The optimizer
+// hint has the server stop the statement too; it runs outside the caller's
+// transaction, which a cancelled statement would leave unusable.
+func (c *MySQLConnector) countRows(ctx context.Context, ref catalog.TableRef, timeout time.Duration) (int64, error) {
+	hint := ""
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		hint = fmt.Sprintf("/*+ MAX_EXECUTION_TIME(%d) */ ", timeout.Milliseconds())
+	}
+
+	var count int64
+	err := c.db.GetContext(ctx, &count, fmt.Sprintf("SELECT %sCOUNT(*) FROM %s", hint, ref.Quote(types.DialectMySQL)))
+	return count, err
+}
+
+// DescribeTable returns detailed information about a specific table
+func (c *MySQLConnector) DescribeTable(ctx context.Context, table string, opts types.DescribeOptions) (catalog.TableRef, *types.TableDescription, error) {
+	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Commit()
 
-	// Get current database name
-	var dbName string
-	err = tx.GetContext(ctx, &dbName, "SELECT DATABASE()")
+	// Resolve the requested name against the catalog
+	ref, err := c.resolveTable(ctx, tx, table)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get database name: %w", err)
+		return catalog.TableRef{}, nil, err
 	}
 
 	// Get columns
-	columns, err := c.loadColumns(ctx, tx, table, dbName)
+	columns, err := c.loadColumns(ctx, tx, ref.Name, ref.Schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load columns: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Get row count
 	var rowCount int64
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM `%s`", table)
-	err = tx.GetContext(ctx, &rowCount, countQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get row count: %w", err)
+	if !opts.SkipRowCount {
+		rowCount, err = c.countRows(ctx, ref, opts.CountTimeout)
+		if err != nil {
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to get row count: %w", err)
+		}
 	}
 
 	// Get sample data
-	sampleData, err := c.Sample(ctx, table, 5)
-	if err != nil {
-		// Non-critical error, continue without sample data
-		sampleData = nil
+	var sampleData []map[string]any
+	if !opts.SkipSampleData {
+		sampleData, err = c.sampleTable(ctx, ref, types.SampleOptions{
+			Limit:    5,
+			MaxBytes: opts.SampleMaxBytes,
+			Timeout:  opts.SampleTimeout,
+		})
+		if err != nil {
+			// Non-critical error, continue without sample data
+			sampleData = nil
+		}
 	}
 
 	// Get primary keys
 	rows, err := tx.QueryContext(ctx, `
 		SELECT column_name
 		FROM information_schema.key_column_usage
-		WHERE table_schema = DATABASE()
+		WHERE table_schema = ?
 		AND table_name = ?
 		AND constraint_name = 'PRIMARY'
-		ORDER BY ordinal_position`, table)
+		ORDER BY ordinal_position`, ref.Schema, ref.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get primary keys: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get primary keys: %w", err)
 	}
 	defer rows.Close()
 
@@ -256,7 +817,7 @@ func (c *MySQLConnector) DescribeTable(ctx context.Context, table string) (*type
 	for rows.Next() {
 		var pkColumn string
 		if err := rows.Scan(&pkColumn); err != nil {
-			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan primary key: %w", err)
 		}
 		primaryKeys = append(primaryKeys, pkColumn)
 	}
@@ -268,12 +829,12 @@ func (c *MySQLConnector) DescribeTable(ctx context.Context, table string) (*type
 			GROUP_CONCAT(column_name ORDER BY seq_in_index) as columns,
 			NOT non_unique as is_unique
 		FROM information_schema.statistics
-		WHERE table_schema = DATABASE()
+		WHERE table_schema = ?
 		AND table_name = ?
 		AND index_name != 'PRIMARY'
-		GROUP BY index_name, non_unique`, table)
+		GROUP BY index_name, non_unique`, ref.Schema, ref.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get indexes: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get indexes: %w", err)
 	}
 	defer indexRows.Close()
 
@@ -283,7 +844,7 @@ func (c *MySQLConnector) DescribeTable(ctx context.Context, table string) (*type
 		var columnNamesStr string
 		var isUnique bool
 		if err := indexRows.Scan(&indexName, &columnNamesStr, &isUnique); err != nil {
-			return nil, fmt.Errorf("failed to scan index: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan index: %w", err)
 		}
 		indexes = append(indexes, types.Index{
 			Name:    indexName,
@@ -292,12 +853,21 @@ func (c *MySQLConnector) DescribeTable(ctx context.Context, table string) (*type
 		})
 	}
 
-	return &types.TableDescription{
-		Name:        table,
+	description := &types.TableDescription{
+		Name:        ref.Quote(types.DialectMySQL),
 		Columns:     columns,
 		RowCount:    rowCount,
 		SampleData:  sampleData,
 		PrimaryKeys: primaryKeys,
 		Indexes:     indexes,
-	}, nil
+	}
+	c.opts.Access.FilterDescription(ref, description)
+
+	keys, err := c.loadKeys(ctx, tx)
+	if err != nil {
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+	catalog.DescribeRelationships(types.DialectMySQL, keys, ref, description)
+
+	return ref, description, nil
 }
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/databases/catalog"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// accessTypes names the access_type values of EXPLAIN FORMAT=JSON.
+var accessTypes = map[string]string{
+	"system":          "System Table",
+	"const":           "Constant Lookup",
+	"eq_ref":          "Unique Index Lookup",
+	"ref":             "Index Lookup",
+	"fulltext":        "Fulltext Index Lookup",
+	"ref_or_null":     "Index Lookup Or Null",
+	"index_merge":     "Index Merge",
+	"unique_subquery": "Unique Subquery",
+	"index_subquery":  "Index Subquery",
+	"range":           "Index Range Scan",
+	"index":           "Full Index Scan",
+	"ALL":             "Full Table Scan",
+}
+
+// parsePlan converts EXPLAIN FORMAT=JSON output. The output nests
+// operations (nested_loop, ordering_operation, ...) as object keys around
+// table entries; each key becomes a node.
+func parsePlan(text string) (*types.QueryPlan, error) {
+	var output map[string]any
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	block, ok := output["query_block"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse plan: no query_block")
+	}
+
+	b := &planBuilder{}
+	root := b.node("query_block", block)
+	return &types.QueryPlan{
+		Dialect:       types.DialectMySQL,
+		TotalCost:     root.Cost,
+		EstimatedRows: b.maxRows,
+		Nodes:         []types.PlanNode{root},
+	}, nil
+}
+
+// planBuilder tracks the largest join result while converting a plan.
+type planBuilder struct {
+	maxRows *float64
+}
+
+func (b *planBuilder) node(key string, obj map[string]any) types.PlanNode {
+	node := types.PlanNode{NodeType: operationName(key)}
+	if costInfo, ok := obj["cost_info"].(map[string]any); ok {
+		node.Cost = catalog.PlanNumber(costInfo["query_cost"])
+		if node.Cost == nil {
+			node.Cost = catalog.PlanNumber(costInfo["prefix_cost"])
+		}
+	}
+	if message, ok := obj["message"].(string); ok {
+		node.Detail = message
+	}
+
+	if key == "table" {
+		accessType, _ := obj["access_type"].(string)
+		if name, ok := accessTypes[accessType]; ok {
+			node.NodeType = name
+		} else if accessType != "" {
+			node.NodeType = accessType
+		}
+		node.Relation, _ = obj["table_name"].(string)
+		node.Index, _ = obj["key"].(string)
+		node.EstimatedRows = catalog.PlanNumber(obj["rows_examined_per_scan"])
+		if condition, ok := obj["attached_condition"].(string); ok {
+			node.Detail = "condition: " + condition
+		}
+
+		if produced := catalog.PlanNumber(obj["rows_produced_per_join"]); produced != nil {
+			if b.maxRows == nil || *produced > *b.maxRows {
+				b.maxRows = produced
+			}
+		}
+	}
+
+	node.Children = b.children(obj)
+	return node
+}
+
+// children converts the operations nested in obj, in key order. Arrays
+// such as nested_loop become one node holding their elements' operations.
+func (b *planBuilder) children(obj map[string]any) []types.PlanNode {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var children []types.PlanNode
+	for _, key := range keys {
+		switch value := obj[key].(type) {
+		case map[string]any:
+			if key == "cost_info" {
+				continue
+			}
+			children = append(children, b.node(key, value))
+		case []any:
+			var nested []types.PlanNode
+			for _, item := range value {
+				if m, ok := item.(map[string]any); ok {
+					nested = append(nested, b.children(m)...)
+				}
+			}
+			if len(nested) > 0 {
+				children = append(children, types.PlanNode{NodeType: operationName(key), Children: nested})
+			}
+		}
+	}
+	return children
+}
+
+// operationName turns a key such as nested_loop into Nested Loop.
+func operationName(key string) string {
+	words := strings.Split(key, "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
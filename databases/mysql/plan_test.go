@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// explainJoin is EXPLAIN FORMAT=JSON output from MySQL 8.0 for
+// SELECT * FROM orders o JOIN users u ON u.id = o.user_id WHERE o.total > 100 ORDER BY o.total
+const explainJoin = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {
+      "query_cost": "1126.25"
+    },
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "o",
+            "access_type": "ALL",
+            "possible_keys": ["user_id"],
+            "rows_examined_per_scan": 2980,
+            "rows_produced_per_join": 993,
+            "filtered": "33.33",
+            "cost_info": {
+              "read_cost": "202.68",
+              "eval_cost": "99.33",
+              "prefix_cost": "302.00",
+              "data_read_per_join": "31K"
+            },
+            "used_columns": ["id", "user_id", "total"],
+            "attached_condition": "(` + "`shop`.`o`.`total`" + ` > 100)"
+          }
+        },
+        {
+          "table": {
+            "table_name": "u",
+            "access_type": "eq_ref",
+            "possible_keys": ["PRIMARY"],
+            "key": "PRIMARY",
+            "used_key_parts": ["id"],
+            "key_length": "4",
+            "ref": ["shop.o.user_id"],
+            "rows_examined_per_scan": 1,
+            "rows_produced_per_join": 993,
+            "filtered": "100.00",
+            "cost_info": {
+              "read_cost": "248.31",
+              "eval_cost": "99.33",
+              "prefix_cost": "649.64",
+              "data_read_per_join": "612K"
+            },
+            "used_columns": ["id", "name"]
+          }
+        }
+      ]
+    }
+  }
+}`
+
+// explainNoTable is EXPLAIN FORMAT=JSON output for SELECT 1
+const explainNoTable = `{
+  "query_block": {
+    "select_id": 1,
+    "message": "No tables used"
+  }
+}`
+
+func float(f float64) *float64 { return &f }
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *types.QueryPlan
+	}{
+		{"nested loop", explainJoin, &types.QueryPlan{
+			Dialect:       types.DialectMySQL,
+			TotalCost:     float(1126.25),
+			EstimatedRows: float(993),
+			Nodes: []types.PlanNode{{
+				NodeType: "Query Block",
+				Cost:     float(1126.25),
+				Children: []types.PlanNode{{
+					NodeType: "Ordering Operation",
+					Children: []types.PlanNode{{
+						NodeType: "Nested Loop",
+						Children: []types.PlanNode{
+							{NodeType: "Full Table Scan", Relation: "o", EstimatedRows: float(2980), Cost: float(302), Detail: "condition: (`shop`.`o`.`total` > 100)"},
+							{NodeType: "Unique Index Lookup", Relation: "u", Index: "PRIMARY", EstimatedRows: float(1), Cost: float(649.64)},
+						},
+					}},
+				}},
+			}},
+		}},
+		{"no tables", explainNoTable, &types.QueryPlan{
+			Dialect: types.DialectMySQL,
+			Nodes:   []types.PlanNode{{NodeType: "Query Block", Detail: "No tables used"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePlan(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePlan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlanErrors(t *testing.T) {
+	for _, output := range []string{"", "[]", `{"select_id": 1}`, "not json"} {
+		if _, err := parsePlan(output); err == nil {
+			t.Errorf("parsePlan(%q) error = nil, want an error", output)
+		}
+	}
+}
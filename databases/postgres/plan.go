@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// explainPlan is one node of EXPLAIN (FORMAT JSON) output.
+type explainPlan struct {
+	NodeType     string        `json:"Node Type"`
+	RelationName string        `json:"Relation Name"`
+	Schema       string        `json:"Schema"`
+	CTEName      string        `json:"CTE Name"`
+	FunctionName string        `json:"Function Name"`
+	IndexName    string        `json:"Index Name"`
+	PlanRows     *float64      `json:"Plan Rows"`
+	TotalCost    *float64      `json:"Total Cost"`
+	JoinType     string        `json:"Join Type"`
+	Strategy     string        `json:"Strategy"`
+	SubplanName  string        `json:"Subplan Name"`
+	IndexCond    string        `json:"Index Cond"`
+	HashCond     string        `json:"Hash Cond"`
+	MergeCond    string        `json:"Merge Cond"`
+	JoinFilter   string        `json:"Join Filter"`
+	Filter       string        `json:"Filter"`
+	SortKey      []string      `json:"Sort Key"`
+	GroupKey     []string      `json:"Group Key"`
+	Plans        []explainPlan `json:"Plans"`
+}
+
+// parsePlan converts EXPLAIN (FORMAT JSON) output.
+func parsePlan(text string) (*types.QueryPlan, error) {
+	var output []struct {
+		Plan explainPlan `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(output) == 0 {
+		return nil, fmt.Errorf("failed to parse plan: no plan returned")
+	}
+
+	root := output[0].Plan
+	return &types.QueryPlan{
+		Dialect:       types.DialectPostgres,
+		TotalCost:     root.TotalCost,
+		EstimatedRows: root.PlanRows,
+		Nodes:         []types.PlanNode{root.node()},
+	}, nil
+}
+
+func (p explainPlan) node() types.PlanNode {
+	node := types.PlanNode{
+		NodeType:      p.NodeType,
+		Index:         p.IndexName,
+		EstimatedRows: p.PlanRows,
+		Cost:          p.TotalCost,
+	}
+
+	switch {
+	case p.RelationName != "" && p.Schema != "":
+		node.Relation = p.Schema + "." + p.RelationName
+	case p.RelationName != "":
+		node.Relation = p.RelationName
+	case p.CTEName != "":
+		node.Relation = p.CTEName
+	case p.FunctionName != "":
+		node.Relation = p.FunctionName
+	}
+
+	var detail []string
+	add := func(label, value string) {
+		if value != "" {
+			detail = append(detail, label+value)
+		}
+	}
+	add("", p.SubplanName)
+	add("join: ", p.JoinType)
+	add("strategy: ", p.Strategy)
+	add("index cond: ", p.IndexCond)
+	add("hash cond: ", p.HashCond)
+	add("merge cond: ", p.MergeCond)
+	add("join filter: ", p.JoinFilter)
+	add("filter: ", p.Filter)
+	add("sort key: ", strings.Join(p.SortKey, ", "))
+	add("group key: ", strings.Join(p.GroupKey, ", "))
+	node.Detail = strings.Join(detail, "; ")
+
+	for _, child := range p.Plans {
+		node.Children = append(node.Children, child.node())
+	}
+	return node
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// explainJoin is EXPLAIN (FORMAT JSON) output for
+// SELECT * FROM orders o JOIN users u ON u.id = o.user_id WHERE o.total > 100
+const explainJoin = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join",
+      "Parallel Aware": false,
+      "Async Capable": false,
+      "Join Type": "Inner",
+      "Startup Cost": 30.48,
+      "Total Cost": 58.96,
+      "Plan Rows": 617,
+      "Plan Width": 120,
+      "Inner Unique": true,
+      "Hash Cond": "(o.user_id = u.id)",
+      "Plans": [
+        {
+          "Node Type": "Seq Scan",
+          "Parent Relationship": "Outer",
+          "Parallel Aware": false,
+          "Async Capable": false,
+          "Relation Name": "orders",
+          "Schema": "public",
+          "Alias": "o",
+          "Startup Cost": 0.00,
+          "Total Cost": 26.88,
+          "Plan Rows": 617,
+          "Plan Width": 56,
+          "Filter": "(total > '100'::numeric)"
+        },
+        {
+          "Node Type": "Hash",
+          "Parent Relationship": "Inner",
+          "Parallel Aware": false,
+          "Async Capable": false,
+          "Startup Cost": 18.80,
+          "Total Cost": 18.80,
+          "Plan Rows": 880,
+          "Plan Width": 64,
+          "Plans": [
+            {
+              "Node Type": "Index Scan",
+              "Parent Relationship": "Outer",
+              "Parallel Aware": false,
+              "Async Capable": false,
+              "Scan Direction": "Forward",
+              "Index Name": "users_pkey",
+              "Relation Name": "users",
+              "Schema": "public",
+              "Alias": "u",
+              "Startup Cost": 0.15,
+              "Total Cost": 18.80,
+              "Plan Rows": 880,
+              "Plan Width": 64
+            }
+          ]
+        }
+      ]
+    }
+  }
+]`
+
+// explainCTE is EXPLAIN (FORMAT JSON) output for
+// WITH t AS MATERIALIZED (SELECT 1) SELECT * FROM t, generate_series(1, 3)
+const explainCTE = `[
+  {
+    "Plan": {
+      "Node Type": "Nested Loop",
+      "Join Type": "Inner",
+      "Startup Cost": 0.01,
+      "Total Cost": 0.11,
+      "Plan Rows": 3,
+      "Plans": [
+        {
+          "Node Type": "Result",
+          "Parent Relationship": "InitPlan",
+          "Subplan Name": "CTE t",
+          "Total Cost": 0.01,
+          "Plan Rows": 1
+        },
+        {
+          "Node Type": "CTE Scan",
+          "Parent Relationship": "Outer",
+          "CTE Name": "t",
+          "Alias": "t",
+          "Total Cost": 0.02,
+          "Plan Rows": 1
+        },
+        {
+          "Node Type": "Function Scan",
+          "Parent Relationship": "Inner",
+          "Function Name": "generate_series",
+          "Alias": "generate_series",
+          "Total Cost": 0.03,
+          "Plan Rows": 3
+        }
+      ]
+    }
+  }
+]`
+
+func float(f float64) *float64 { return &f }
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *types.QueryPlan
+	}{
+		{"nested plans", explainJoin, &types.QueryPlan{
+			Dialect:       types.DialectPostgres,
+			TotalCost:     float(58.96),
+			EstimatedRows: float(617),
+			Nodes: []types.PlanNode{{
+				NodeType: "Hash Join", EstimatedRows: float(617), Cost: float(58.96),
+				Detail: "join: Inner; hash cond: (o.user_id = u.id)",
+				Children: []types.PlanNode{
+					{NodeType: "Seq Scan", Relation: "public.orders", EstimatedRows: float(617), Cost: float(26.88), Detail: "filter: (total > '100'::numeric)"},
+					{NodeType: "Hash", EstimatedRows: float(880), Cost: float(18.80), Children: []types.PlanNode{
+						{NodeType: "Index Scan", Relation: "public.users", Index: "users_pkey", EstimatedRows: float(880), Cost: float(18.80)},
+					}},
+				},
+			}},
+		}},
+		{"cte and function scans", explainCTE, &types.QueryPlan{
+			Dialect:       types.DialectPostgres,
+			TotalCost:     float(0.11),
+			EstimatedRows: float(3),
+			Nodes: []types.PlanNode{{
+				NodeType: "Nested Loop", EstimatedRows: float(3), Cost: float(0.11), Detail: "join: Inner",
+				Children: []types.PlanNode{
+					{NodeType: "Result", EstimatedRows: float(1), Cost: float(0.01), Detail: "CTE t"},
+					{NodeType: "CTE Scan", Relation: "t", EstimatedRows: float(1), Cost: float(0.02)},
+					{NodeType: "Function Scan", Relation: "generate_series", EstimatedRows: float(3), Cost: float(0.03)},
+				},
+			}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePlan(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePlan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlanErrors(t *testing.T) {
+	for _, output := range []string{"", "[]", `{"Plan": {}}`, "not json"} {
+		if _, err := parsePlan(output); err == nil {
+			t.Errorf("parsePlan(%q) error = nil, want an error", output)
+		}
+	}
+}
@@ -4,20 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/melkeydev/mcp-database/databases/catalog"
 	"github.com/melkeydev/mcp-database/types"
 )
 
 type PostgresConnector struct {
-	db *sqlx.DB
-	// schema string
+	db    *sqlx.DB
+	opts  catalog.Options
+	cache *catalog.Cache
 }
 
-func NewPostgresConnector(connectionString string) (*PostgresConnector, error) {
+func NewPostgresConnector(ctx context.Context, connectionString string, opts catalog.Options) (*PostgresConnector, error) {
 	config, err := pgx.ParseConfig(connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse connection string: %w", err)
@@ -28,12 +32,13 @@ func NewPostgresConnector(connectionString string) (*PostgresConnector, error) {
 	db := sqlx.NewDb(stdlib.OpenDB(*config), "pgx")
 
 	connector := &PostgresConnector{
-		db: db,
-		// schema: schema,
+		db:    db,
+		opts:  opts,
+		cache: catalog.NewCache(opts.CacheTTL),
 	}
 
 	// Test the connection
-	if err := connector.Ping(context.Background()); err != nil {
+	if err := connector.Ping(ctx); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -45,76 +50,62 @@ func (c *PostgresConnector) Ping(ctx context.Context) error {
 	return c.db.PingContext(ctx)
 }
 
+func (c *PostgresConnector) Dialect() types.Dialect {
+	return types.DialectPostgres
+}
+
 // Discover
-func (c *PostgresConnector) Scan(ctx context.Context, tablesList []string) ([]types.Table, error) {
+func (c *PostgresConnector) Scan(ctx context.Context, tablesList []string) (*types.ScanResult, error) {
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Commit()
 
-	var query string
-	var args []interface{}
+	all, err := c.listTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
 
+	matched := catalog.MatchResult{Tables: all}
 	if len(tablesList) > 0 {
-		// Query specific tables
-		placeholders := make([]string, len(tablesList))
-		args = make([]interface{}, len(tablesList))
-
-		for i, table := range tablesList {
-			placeholders[i] = fmt.Sprintf("$%d", i+1)
-			args[i] = table
+		currentSchema, err := c.currentSchema(ctx, tx)
+		if err != nil {
+			return nil, err
 		}
-
-		query = fmt.Sprintf(`
-			SELECT table_name, table_schema
-			FROM information_schema.tables 
-			WHERE table_type = 'BASE TABLE'
-			AND table_name IN (%s)
-		`, strings.Join(placeholders, ","))
-
-	} else {
-		// Query all tables
-		query = `
-			SELECT table_name, table_schema
-			FROM information_schema.tables 
-			WHERE table_type = 'BASE TABLE'
-		`
+		matched = catalog.Match(types.DialectPostgres, tablesList, all, currentSchema)
 	}
 
-	rows, err := tx.QueryContext(ctx, query, args...)
+	result := &types.ScanResult{
+		Tables:      []types.Table{},
+		NotFound:    matched.NotFound,
+		Suggestions: matched.Suggestions,
+	}
+	columns, err := c.loadTableColumns(ctx, tx, matched.Tables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
+		return nil, fmt.Errorf("failed to load columns: %w", err)
 	}
-	defer rows.Close()
-
-	var tables []types.Table
-	for rows.Next() {
-		var tableName, tableSchema string
-		if err := rows.Scan(&tableName, &tableSchema); err != nil {
-			return nil, fmt.Errorf("failed to scan table: %w", err)
-		}
-
-		columns, err := c.loadColumns(ctx, tx, tableName, tableSchema)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load columns: %w", err)
-		}
-
-		fqtn := fmt.Sprintf(`"%s"."%s"`, tableSchema, tableName)
-		tables = append(tables, types.Table{
-			Name:    fqtn,
-			Columns: columns,
+	for i, ref := range matched.Tables {
+		result.Tables = append(result.Tables, types.Table{
+			Name:    ref.Quote(types.DialectPostgres),
+			Kind:    ref.Kind(),
+			Columns: columns[i],
 		})
 	}
 
-	return tables, nil
+	return result, nil
 }
 
 // Query
-func (c *PostgresConnector) Query(ctx context.Context, sqlQuery string) ([]map[string]any, error) {
+func (c *PostgresConnector) Query(ctx context.Context, sqlQuery string, opts types.QueryOptions) (*types.QueryResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
@@ -124,32 +115,122 @@ func (c *PostgresConnector) Query(ctx context.Context, sqlQuery string) ([]map[s
 	}
 	defer tx.Commit()
 
-	rows, err := tx.QueryxContext(ctx, sqlQuery)
+	// The server enforces the timeout too, so the statement stops even if
+	// the cancel request sent on context expiry is lost
+	if opts.Timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", opts.Timeout.Milliseconds())); err != nil {
+			return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	}
+
+	queryCtx, cancelQuery := context.WithCancel(ctx)
+	defer cancelQuery()
+
+	rows, err := tx.QueryxContext(queryCtx, sqlQuery)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query db: %w", err)
 	}
 	defer rows.Close()
 
-	var results []map[string]any
-	for rows.Next() {
-		row := make(map[string]any)
-		if err := rows.MapScan(row); err != nil {
-			return nil, fmt.Errorf("unable to scan row: %w", err)
+	return catalog.ReadRows(rows, opts, cancelQuery)
+}
+
+// Explain returns the planner's plan for a query without running it
+func (c *PostgresConnector) Explain(ctx context.Context, sqlQuery string, timeout time.Duration) (*types.QueryPlan, error) {
+	result, err := c.Query(ctx, "EXPLAIN (FORMAT JSON) "+sqlQuery, types.QueryOptions{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	text, err := catalog.PlanText(result)
+	if err != nil {
+		return nil, err
+	}
+	return parsePlan(text)
+}
+
+// Sample
+func (c *PostgresConnector) Sample(ctx context.Context, table string, opts types.SampleOptions) (catalog.TableRef, []map[string]any, error) {
+	ref, err := c.resolveTable(ctx, c.db, table)
+	if err != nil {
+		return catalog.TableRef{}, nil, err
+	}
+
+	rows, err := c.sampleTable(ctx, ref, opts)
+	return ref, rows, err
+}
+
+func (c *PostgresConnector) sampleTable(ctx context.Context, ref catalog.TableRef, opts types.SampleOptions) ([]map[string]any, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	columns, err := c.loadColumns(ctx, c.db, ref.Name, ref.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load columns: %w", err)
+	}
+
+	if opts.Where != "" {
+		check := fmt.Sprintf("SELECT 1 FROM %s WHERE (%s\n)", ref.Quote(types.DialectPostgres), opts.Where)
+		if err := c.CheckAccess(ctx, check); err != nil {
+			return nil, fmt.Errorf("invalid where clause: %w", err)
+		}
+	}
+
+	sample := catalog.SampleQuery{
+		Dialect:     types.DialectPostgres,
+		Table:       ref,
+		Columns:     columns,
+		ListColumns: !c.opts.Access.IsZero(),
+	}
+
+	if opts.Random {
+		sample.TableSample, err = c.tableSample(ctx, ref, opts.Limit)
+		if err != nil {
+			return nil, err
 		}
-		results = append(results, row)
 	}
 
-	return results, nil
+	query, err := sample.Build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnQuery != nil {
+		opts.OnQuery(query)
+	}
+
+	result, err := c.Query(ctx, query, types.QueryOptions{
+		MaxRows:  opts.Limit,
+		MaxBytes: opts.MaxBytes,
+		Timeout:  opts.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
 }
 
-// Sample
-func (c *PostgresConnector) Sample(ctx context.Context, table string, limit int) ([]map[string]any, error) {
-	if limit <= 0 {
-		limit = 10
+// tableSample returns a TABLESAMPLE clause for large tables so random
+// samples don't have to sort the whole table. Small tables and views get
+// no clause and rely on ORDER BY RANDOM() alone.
+func (c *PostgresConnector) tableSample(ctx context.Context, ref catalog.TableRef, limit int) (string, error) {
+	var estimate float64
+	err := c.db.GetContext(ctx, &estimate, `
+		SELECT COALESCE(MAX(c.reltuples), 0)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relname = $2
+		AND c.relkind IN ('r', 'm', 'p')`, ref.Schema, ref.Name)
+	if err != nil {
+		return "", fmt.Errorf("failed to estimate row count: %w", err)
+	}
+
+	if estimate < 100000 {
+		return "", nil
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d", table, limit)
-	return c.Query(ctx, query)
+	// Oversample tenfold so the LIMIT is still filled after sampling
+	percent := math.Min(100, float64(limit)*10*100/estimate)
+	return fmt.Sprintf("TABLESAMPLE BERNOULLI (%s)", strconv.FormatFloat(percent, 'f', -1, 64)), nil
 }
 
 func (c *PostgresConnector) Close() error {
@@ -159,91 +240,571 @@ func (c *PostgresConnector) Close() error {
 	return nil
 }
 
-func (c *PostgresConnector) loadColumns(ctx context.Context, tx *sqlx.Tx, tableName, tableSchema string) ([]types.Column, error) {
-	query := `
-		SELECT column_name, data_type, is_nullable
-		FROM information_schema.columns
-		WHERE table_name = $1 AND table_schema = $2
-		ORDER BY ordinal_position
-	`
+// listTables returns every table, view and materialized view in the
+// schemas allowed by the schema filter. Materialized views are missing
+// from information_schema and come from pg_matviews.
+func (c *PostgresConnector) listTables(ctx context.Context, q sqlx.QueryerContext) ([]catalog.TableRef, error) {
+	return c.cache.Tables(func() ([]catalog.TableRef, error) {
+		var all []catalog.TableRef
+		err := sqlx.SelectContext(ctx, q, &all, `
+			SELECT table_schema, table_name, table_type
+			FROM information_schema.tables
+			UNION ALL
+			SELECT schemaname, matviewname, 'MATERIALIZED VIEW'
+			FROM pg_matviews
+			ORDER BY table_schema, table_name
+		`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		var tables []catalog.TableRef
+		for _, ref := range all {
+			if c.opts.Schemas.Allows(types.DialectPostgres, ref.Schema) && c.opts.Access.TableVisible(ref) {
+				tables = append(tables, ref)
+			}
+		}
+		return tables, nil
+	})
+}
+
+// RefreshSchema drops cached catalog entries for the named tables, or for
+// every table when none are named
+func (c *PostgresConnector) RefreshSchema(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	return c.cache.Refresh(tables, func(name string) (catalog.TableRef, error) {
+		return c.resolveTable(ctx, c.db, name)
+	})
+}
+
+// CheckAccess rejects a query that references a hidden table or column
+func (c *PostgresConnector) CheckAccess(ctx context.Context, query string) error {
+	return c.opts.Access.CheckQuery(types.DialectPostgres, query, catalog.AccessLookup{
+		Resolve: func(name string) (catalog.TableRef, error) {
+			return c.resolveTable(ctx, c.db, name)
+		},
+		Columns: func(ref catalog.TableRef) ([]types.Column, error) {
+			columns, err := c.loadAllColumns(ctx, c.db, []catalog.TableRef{ref})
+			if err != nil {
+				return nil, err
+			}
+			return columns[0], nil
+		},
+	})
+}
+
+// ListTables returns every visible table and view
+func (c *PostgresConnector) ListTables(ctx context.Context) ([]catalog.TableRef, error) {
+	return c.listTables(ctx, c.db)
+}
+
+// ListSchemas returns the schemas allowed by the schema filter
+func (c *PostgresConnector) ListSchemas(ctx context.Context) ([]types.Schema, error) {
+	names, err := c.listSchemaNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	currentSchema, err := c.currentSchema(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.Schemas(names, tables, currentSchema), nil
+}
+
+// listSchemaNames returns the names of the schemas allowed by the filter.
+func (c *PostgresConnector) listSchemaNames(ctx context.Context) ([]string, error) {
+	return c.cache.Schemas(func() ([]string, error) {
+		var all []string
+		err := c.db.SelectContext(ctx, &all, `SELECT nspname FROM pg_namespace ORDER BY nspname`)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schemas: %w", err)
+		}
+
+		var names []string
+		for _, name := range all {
+			if c.opts.Schemas.Allows(types.DialectPostgres, name) {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	})
+}
+
+// resolveTable maps a caller supplied table name onto a catalog entry,
+// defaulting unqualified names to the current schema.
+func (c *PostgresConnector) resolveTable(ctx context.Context, q sqlx.QueryerContext, table string) (catalog.TableRef, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return catalog.TableRef{}, err
+	}
+
+	currentSchema, err := c.currentSchema(ctx, q)
+	if err != nil {
+		return catalog.TableRef{}, err
+	}
+
+	return catalog.Resolve(types.DialectPostgres, table, tables, currentSchema)
+}
+
+// currentSchema returns the first schema on the search path, which is where
+// unqualified table names are looked up.
+func (c *PostgresConnector) currentSchema(ctx context.Context, q sqlx.QueryerContext) (string, error) {
+	return c.cache.DefaultSchema(func() (string, error) {
+		var currentSchema string
+		if err := sqlx.GetContext(ctx, q, &currentSchema, "SELECT current_schema()"); err != nil {
+			return "", fmt.Errorf("failed to get current schema: %w", err)
+		}
+		return currentSchema, nil
+	})
+}
+
+// loadColumns returns the columns of a single table.
+func (c *PostgresConnector) loadColumns(ctx context.Context, q sqlx.QueryerContext, tableName, tableSchema string) ([]types.Column, error) {
+	columns, err := c.loadTableColumns(ctx, q, []catalog.TableRef{{Schema: tableSchema, Name: tableName}})
+	if err != nil {
+		return nil, err
+	}
+	return columns[0], nil
+}
 
-	rows, err := tx.QueryContext(ctx, query, tableName, tableSchema)
+// loadTableColumns returns the visible columns of every table in refs.
+func (c *PostgresConnector) loadTableColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	columns, err := c.loadAllColumns(ctx, q, refs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query columns: %w", err)
+		return nil, err
+	}
+	for i, ref := range refs {
+		columns[i] = c.opts.Access.VisibleColumns(ref, columns[i])
+	}
+	return columns, nil
+}
+
+// loadAllColumns returns the columns of every table in refs, hidden or not,
+// reading the uncached ones with a single catalog query.
+func (c *PostgresConnector) loadAllColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	return c.cache.Columns(refs, func(missing []catalog.TableRef, add func(schema, name string, column types.Column)) error {
+		schemas := make([]string, len(missing))
+		names := make([]string, len(missing))
+		for i, ref := range missing {
+			schemas[i] = ref.Schema
+			names[i] = ref.Name
+		}
+
+		// information_schema.columns leaves out materialized views, whose
+		// columns are read from pg_attribute instead
+		query := `
+			SELECT c.table_schema, c.table_name, c.column_name, c.data_type, c.is_nullable, c.ordinal_position
+			FROM information_schema.columns c
+			JOIN unnest($1::text[], $2::text[]) AS t(table_schema, table_name)
+				ON c.table_schema = t.table_schema AND c.table_name = t.table_name
+			UNION ALL
+			SELECT n.nspname, cl.relname, a.attname, format_type(a.atttypid, a.atttypmod),
+				CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END, a.attnum
+			FROM pg_attribute a
+			JOIN pg_class cl ON cl.oid = a.attrelid
+			JOIN pg_namespace n ON n.oid = cl.relnamespace
+			JOIN unnest($1::text[], $2::text[]) AS t(table_schema, table_name)
+				ON n.nspname = t.table_schema AND cl.relname = t.table_name
+			WHERE cl.relkind = 'm' AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY 1, 2, 6
+		`
+
+		rows, err := q.QueryContext(ctx, query, schemas, names)
+		if err != nil {
+			return fmt.Errorf("failed to query columns: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var schema, table, name, dataType, isNullable string
+			var position int
+			if err := rows.Scan(&schema, &table, &name, &dataType, &isNullable, &position); err != nil {
+				return fmt.Errorf("failed to scan column: %w", err)
+			}
+
+			add(schema, table, types.Column{
+				Name:     name,
+				Type:     dataType,
+				Nullable: isNullable == "YES",
+			})
+		}
+
+		return rows.Err()
+	})
+}
+
+// Relationships returns the declared and inferred relationships involving
+// the named tables, or every relationship when none are named.
+func (c *PostgresConnector) Relationships(ctx context.Context, tables []string) ([]types.Relationship, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Relationships(types.DialectPostgres, keys, refs), nil
+}
+
+// JoinPaths suggests how to join the named tables through their
+// relationships, best first.
+func (c *PostgresConnector) JoinPaths(ctx context.Context, tables []string, opts types.JoinPathOptions) ([]types.JoinPath, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.JoinPaths(types.DialectPostgres, keys, refs, opts)
+}
+
+// SchemaGraph returns the tables selected by opts with their columns,
+// primary keys and relationships, for drawing a diagram.
+func (c *PostgresConnector) SchemaGraph(ctx context.Context, opts types.DiagramOptions) (*types.SchemaGraph, error) {
+	only, err := c.resolveTables(ctx, opts.Tables)
+	if err != nil {
+		return nil, err
+	}
+	var focus *catalog.TableRef
+	if opts.Focus != "" {
+		ref, err := c.resolveTable(ctx, c.db, opts.Focus)
+		if err != nil {
+			return nil, err
+		}
+		focus = &ref
+	}
+
+	all, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := catalog.DiagramTables(all, keys, only, focus, opts.Hops, opts.IncludeInferred)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.loadTableColumns(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, err := c.loadPrimaryKeys(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.SchemaGraph(types.DialectPostgres, tables, columns, primaryKeys, keys, opts.IncludeInferred), nil
+}
+
+// loadPrimaryKeys returns the primary key columns of the tables in refs,
+// keyed by schema and name.
+func (c *PostgresConnector) loadPrimaryKeys(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) (map[catalog.TableRef][]string, error) {
+	schemas := make([]string, len(refs))
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		schemas[i] = ref.Schema
+		names[i] = ref.Name
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT n.nspname, c.relname, a.attname
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN unnest($1::text[], $2::text[]) AS t(table_schema, table_name)
+			ON n.nspname = t.table_schema AND c.relname = t.table_name
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indisprimary
+		ORDER BY n.nspname, c.relname, array_position(i.indkey, a.attnum)
+	`, schemas, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query primary keys: %w", err)
 	}
 	defer rows.Close()
 
-	var columns []types.Column
+	primaryKeys := make(map[catalog.TableRef][]string)
 	for rows.Next() {
-		var name, dataType, isNullable string
-		if err := rows.Scan(&name, &dataType, &isNullable); err != nil {
-			return nil, fmt.Errorf("failed to scan column: %w", err)
+		var ref catalog.TableRef
+		var column string
+		if err := rows.Scan(&ref.Schema, &ref.Name, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan primary key: %w", err)
 		}
+		primaryKeys[ref] = append(primaryKeys[ref], column)
+	}
+	return primaryKeys, rows.Err()
+}
 
-		columns = append(columns, types.Column{
-			Name:     name,
-			Type:     dataType,
-			Nullable: isNullable == "YES",
-		})
+// ListObjects returns the views, materialized views, functions,
+// procedures and triggers of the given kinds, or of every kind when none
+// are given, without their definitions.
+func (c *PostgresConnector) ListObjects(ctx context.Context, kinds []string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
 	}
+	return catalog.FilterObjects(objects, kinds)
+}
 
-	return columns, nil
+// DescribeObject returns the objects called name, optionally only those of
+// kind, with their definitions.
+func (c *PostgresConnector) DescribeObject(ctx context.Context, name, kind string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	currentSchema, err := c.currentSchema(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.FindObjects(types.DialectPostgres, objects, name, kind, currentSchema)
 }
 
-// DescribeTable returns detailed information about a specific table
-func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*types.TableDescription, error) {
+// objectQueries read the views, materialized views, routines and triggers
+// of the schemas in $1. Functions and procedures installed by extensions
+// are left out, as are aggregates and window functions, which have no
+// definition to show.
+var objectQueries = []string{`
+	SELECT
+		n.nspname AS object_schema,
+		c.relname AS object_name,
+		CASE c.relkind WHEN 'm' THEN 'materialized view' ELSE 'view' END AS object_kind,
+		'' AS table_name,
+		'' AS signature,
+		'' AS language,
+		pg_get_viewdef(c.oid, true) AS definition
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE c.relkind IN ('v', 'm')
+	AND n.nspname = ANY($1)
+	ORDER BY n.nspname, c.relname
+`, `
+	SELECT
+		n.nspname AS object_schema,
+		p.proname AS object_name,
+		CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END AS object_kind,
+		'' AS table_name,
+		'(' || pg_get_function_identity_arguments(p.oid) || ')' || COALESCE(' RETURNS ' || pg_get_function_result(p.oid), '') AS signature,
+		l.lanname AS language,
+		pg_get_functiondef(p.oid) AS definition
+	FROM pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	JOIN pg_language l ON l.oid = p.prolang
+	WHERE p.prokind IN ('f', 'p')
+	AND n.nspname = ANY($1)
+	AND NOT EXISTS (
+		SELECT 1 FROM pg_depend d
+		WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e'
+	)
+	ORDER BY n.nspname, p.proname, 5
+`, `
+	SELECT
+		n.nspname AS object_schema,
+		t.tgname AS object_name,
+		'trigger' AS object_kind,
+		c.relname AS table_name,
+		'' AS signature,
+		'' AS language,
+		pg_get_triggerdef(t.oid, true) AS definition
+	FROM pg_trigger t
+	JOIN pg_class c ON c.oid = t.tgrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE NOT t.tgisinternal
+	AND n.nspname = ANY($1)
+	ORDER BY n.nspname, t.tgname
+`}
+
+// loadObjects returns the views, materialized views, functions,
+// procedures and triggers of the visible schemas with their definitions.
+func (c *PostgresConnector) loadObjects(ctx context.Context, q sqlx.QueryerContext) ([]types.SchemaObject, error) {
+	schemas, err := c.listSchemaNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []types.SchemaObject
+	for _, query := range objectQueries {
+		var found []types.SchemaObject
+		if err := sqlx.SelectContext(ctx, q, &found, query, schemas); err != nil {
+			return nil, fmt.Errorf("failed to list schema objects: %w", err)
+		}
+		objects = append(objects, found...)
+	}
+	return catalog.VisibleObjects(objects, tables), nil
+}
+
+// resolveTables maps each of the caller supplied table names onto a
+// catalog entry.
+func (c *PostgresConnector) resolveTables(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	refs := make([]catalog.TableRef, 0, len(tables))
+	for _, name := range tables {
+		ref, err := c.resolveTable(ctx, c.db, name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// loadKeys returns the visible declared foreign keys followed by the
+// inferred ones.
+func (c *PostgresConnector) loadKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := c.cache.ForeignKeys(func() ([]catalog.ForeignKey, error) {
+		return c.loadForeignKeys(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.loadTableColumns(ctx, q, tables)
+	if err != nil {
+		return nil, err
+	}
+	return c.opts.Access.Keys(tables, columns, declared), nil
+}
+
+// loadForeignKeys reads every foreign key from pg_constraint. The
+// information_schema views only list constraints on tables the role can
+// modify, which would hide them all from a read-only role.
+func (c *PostgresConnector) loadForeignKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	var columns []catalog.KeyColumn
+	err := sqlx.SelectContext(ctx, q, &columns, `
+		SELECT
+			0 AS constraint_id,
+			con.conname AS constraint_name,
+			ns.nspname AS table_schema,
+			cl.relname AS table_name,
+			att.attname AS column_name,
+			fns.nspname AS referenced_table_schema,
+			fcl.relname AS referenced_table_name,
+			fatt.attname AS referenced_column_name,
+			CASE con.confupdtype
+				WHEN 'r' THEN 'RESTRICT'
+				WHEN 'c' THEN 'CASCADE'
+				WHEN 'n' THEN 'SET NULL'
+				WHEN 'd' THEN 'SET DEFAULT'
+				ELSE 'NO ACTION'
+			END AS update_rule,
+			CASE con.confdeltype
+				WHEN 'r' THEN 'RESTRICT'
+				WHEN 'c' THEN 'CASCADE'
+				WHEN 'n' THEN 'SET NULL'
+				WHEN 'd' THEN 'SET DEFAULT'
+				ELSE 'NO ACTION'
+			END AS delete_rule
+		FROM pg_constraint con
+		JOIN pg_class cl ON cl.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+		JOIN pg_class fcl ON fcl.oid = con.confrelid
+		JOIN pg_namespace fns ON fns.oid = fcl.relnamespace
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, fattnum, position)
+		JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = k.attnum
+		JOIN pg_attribute fatt ON fatt.attrelid = con.confrelid AND fatt.attnum = k.fattnum
+		WHERE con.contype = 'f'
+		ORDER BY ns.nspname, cl.relname, con.conname, k.position
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	return catalog.GroupKeys(columns), nil
+}
+
+// countRows counts the rows of ref, giving up after timeout. It runs in a
+// transaction of its own, as a statement timeout aborts the transaction it
+// happens in.
+func (c *PostgresConnector) countRows(ctx context.Context, ref catalog.TableRef, timeout time.Duration) (int64, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Commit()
 
-	// Parse table name to extract schema and table
-	parts := strings.Split(table, ".")
-	var tableSchema, tableName string
-	if len(parts) == 2 {
-		tableSchema = strings.Trim(parts[0], `"`)
-		tableName = strings.Trim(parts[1], `"`)
-	} else {
-		tableSchema = "public"
-		tableName = strings.Trim(table, `"`)
+	if timeout > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+			return 0, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
 	}
 
-	// Check if table exists
-	var exists bool
-	err = tx.GetContext(ctx, &exists, `
-		SELECT EXISTS (
-			SELECT 1 FROM information_schema.tables 
-			WHERE table_schema = $1 AND table_name = $2
-		)`, tableSchema, tableName)
+	var count int64
+	err = tx.GetContext(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", ref.Quote(types.DialectPostgres)))
+	return count, err
+}
+
+// DescribeTable returns detailed information about a specific table
+func (c *PostgresConnector) DescribeTable(ctx context.Context, table string, opts types.DescribeOptions) (catalog.TableRef, *types.TableDescription, error) {
+	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
+		ReadOnly: true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to check table existence: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if !exists {
-		return nil, fmt.Errorf("table %s not found", table)
+	defer tx.Commit()
+
+	// Resolve the requested name against the catalog
+	ref, err := c.resolveTable(ctx, tx, table)
+	if err != nil {
+		return catalog.TableRef{}, nil, err
 	}
+	tableSchema, tableName := ref.Schema, ref.Name
 
 	// Get columns
 	columns, err := c.loadColumns(ctx, tx, tableName, tableSchema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load columns: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Get row count
 	var rowCount int64
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"."%s"`, tableSchema, tableName)
-	err = tx.GetContext(ctx, &rowCount, countQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get row count: %w", err)
+	if !opts.SkipRowCount {
+		rowCount, err = c.countRows(ctx, ref, opts.CountTimeout)
+		if err != nil {
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to get row count: %w", err)
+		}
 	}
 
 	// Get sample data
-	sampleData, err := c.Sample(ctx, table, 5)
-	if err != nil {
-		// Non-critical error, continue without sample data
-		sampleData = nil
+	var sampleData []map[string]any
+	if !opts.SkipSampleData {
+		sampleData, err = c.sampleTable(ctx, ref, types.SampleOptions{
+			Limit:    5,
+			MaxBytes: opts.SampleMaxBytes,
+			Timeout:  opts.SampleTimeout,
+		})
+		if err != nil {
+			// Non-critical error, continue without sample data
+			sampleData = nil
+		}
 	}
 
 	// Get primary keys
@@ -258,7 +819,7 @@ func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*t
 		AND c.relname = $2
 		ORDER BY array_position(i.indkey, a.attnum)`, tableSchema, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get primary keys: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get primary keys: %w", err)
 	}
 	defer rows.Close()
 
@@ -266,7 +827,7 @@ func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*t
 	for rows.Next() {
 		var pkColumn string
 		if err := rows.Scan(&pkColumn); err != nil {
-			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan primary key: %w", err)
 		}
 		primaryKeys = append(primaryKeys, pkColumn)
 	}
@@ -287,7 +848,7 @@ func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*t
 		AND NOT idx.indisprimary
 		GROUP BY i.relname, idx.indisunique`, tableSchema, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get indexes: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get indexes: %w", err)
 	}
 	defer indexRows.Close()
 
@@ -297,7 +858,7 @@ func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*t
 		var columnNames []string
 		var isUnique bool
 		if err := indexRows.Scan(&indexName, &columnNames, &isUnique); err != nil {
-			return nil, fmt.Errorf("failed to scan index: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan index: %w", err)
 		}
 		indexes = append(indexes, types.Index{
 			Name:    indexName,
@@ -306,12 +867,21 @@ func (c *PostgresConnector) DescribeTable(ctx context.Context, table string) (*t
 		})
 	}
 
-	return &types.TableDescription{
-		Name:        table,
+	description := &types.TableDescription{
+		Name:        ref.Quote(types.DialectPostgres),
 		Columns:     columns,
 		RowCount:    rowCount,
 		SampleData:  sampleData,
 		PrimaryKeys: primaryKeys,
 		Indexes:     indexes,
-	}, nil
+	}
+	c.opts.Access.FilterDescription(ref, description)
+
+	keys, err := c.loadKeys(ctx, tx)
+	if err != nil {
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+	catalog.DescribeRelationships(types.DialectPostgres, keys, ref, description)
+
+	return ref, description, nil
 }
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// planRow is one row of EXPLAIN QUERY PLAN output.
+type planRow struct {
+	ID     int64
+	Parent int64
+	Detail string
+}
+
+// parsePlan builds the plan tree from EXPLAIN QUERY PLAN rows. SQLite
+// gives no cost or row estimates, only a line per step such as
+// "SEARCH users USING INDEX idx_email (email=?)".
+func parsePlan(rows []map[string]any) (*types.QueryPlan, error) {
+	var steps []planRow
+	for _, row := range rows {
+		id, ok1 := row["id"].(int64)
+		parent, ok2 := row["parent"].(int64)
+		detail, ok3 := row["detail"].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return nil, fmt.Errorf("unexpected EXPLAIN QUERY PLAN row: %v", row)
+		}
+		steps = append(steps, planRow{ID: id, Parent: parent, Detail: detail})
+	}
+
+	return &types.QueryPlan{
+		Dialect: types.DialectSQLite,
+		Nodes:   planChildren(steps, 0),
+	}, nil
+}
+
+func planChildren(steps []planRow, parent int64) []types.PlanNode {
+	var nodes []types.PlanNode
+	for _, step := range steps {
+		if step.Parent != parent || step.ID == parent {
+			continue
+		}
+		node := planNode(step.Detail)
+		node.Children = planChildren(steps, step.ID)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// planNode reads the operation, table and index from a plan line.
+func planNode(detail string) types.PlanNode {
+	words := strings.Fields(detail)
+	if len(words) < 2 || (words[0] != "SCAN" && words[0] != "SEARCH") {
+		return types.PlanNode{NodeType: detail}
+	}
+
+	node := types.PlanNode{NodeType: "Scan", Detail: detail}
+	if words[0] == "SEARCH" {
+		node.NodeType = "Search"
+	}
+
+	i := 1
+	if words[i] == "TABLE" && i+1 < len(words) {
+		// Before SQLite 3.36 lines read "SCAN TABLE users"
+		i++
+	}
+	if words[i] != "CONSTANT" && words[i] != "SUBQUERY" {
+		node.Relation = words[i]
+	}
+
+	using := strings.Index(detail, " USING ")
+	if using < 0 {
+		return node
+	}
+	rest := detail[using+len(" USING "):]
+	switch {
+	case strings.HasPrefix(rest, "INTEGER PRIMARY KEY"):
+		node.Index = "INTEGER PRIMARY KEY"
+	case strings.HasPrefix(rest, "PRIMARY KEY"):
+		node.Index = "PRIMARY KEY"
+	default:
+		fields := strings.Fields(rest)
+		for j, f := range fields {
+			if f != "INDEX" || j+1 >= len(fields) {
+				continue
+			}
+			node.Index = fields[j+1]
+			if strings.HasPrefix(node.Index, "(") || strings.Contains(rest, "AUTOMATIC") {
+				node.Index = "automatic index"
+			}
+			break
+		}
+	}
+	return node
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// step is one EXPLAIN QUERY PLAN row as the driver returns it.
+func step(id, parent int64, detail string) map[string]any {
+	return map[string]any{"id": id, "parent": parent, "notused": int64(0), "detail": detail}
+}
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []map[string]any
+		nodes []types.PlanNode
+	}{
+		{
+			// SQLite 3.40: SELECT * FROM orders o JOIN users u ON u.id = o.user_id ORDER BY o.total
+			"join",
+			[]map[string]any{
+				step(4, 0, "SCAN o"),
+				step(6, 0, "SEARCH u USING INTEGER PRIMARY KEY (rowid=?)"),
+				step(20, 0, "USE TEMP B-TREE FOR ORDER BY"),
+			},
+			[]types.PlanNode{
+				{NodeType: "Scan", Relation: "o", Detail: "SCAN o"},
+				{NodeType: "Search", Relation: "u", Index: "INTEGER PRIMARY KEY", Detail: "SEARCH u USING INTEGER PRIMARY KEY (rowid=?)"},
+				{NodeType: "USE TEMP B-TREE FOR ORDER BY"},
+			},
+		},
+		{
+			// SQLite 3.31, before the TABLE keyword was dropped
+			"old format",
+			[]map[string]any{
+				step(3, 0, "SCAN TABLE orders AS o"),
+				step(5, 0, "SEARCH TABLE users AS u USING INDEX idx_email (email=?)"),
+				step(9, 0, "SEARCH TABLE shipments USING PRIMARY KEY (order_id=?)"),
+			},
+			[]types.PlanNode{
+				{NodeType: "Scan", Relation: "orders", Detail: "SCAN TABLE orders AS o"},
+				{NodeType: "Search", Relation: "users", Index: "idx_email", Detail: "SEARCH TABLE users AS u USING INDEX idx_email (email=?)"},
+				{NodeType: "Search", Relation: "shipments", Index: "PRIMARY KEY", Detail: "SEARCH TABLE shipments USING PRIMARY KEY (order_id=?)"},
+			},
+		},
+		{
+			"automatic index",
+			[]map[string]any{
+				step(3, 0, "SCAN o"),
+				step(17, 0, "SEARCH p USING AUTOMATIC COVERING INDEX (user_id=?)"),
+				step(21, 0, "SEARCH TABLE q USING AUTOMATIC PARTIAL COVERING INDEX (user_id=?)"),
+			},
+			[]types.PlanNode{
+				{NodeType: "Scan", Relation: "o", Detail: "SCAN o"},
+				{NodeType: "Search", Relation: "p", Index: "automatic index", Detail: "SEARCH p USING AUTOMATIC COVERING INDEX (user_id=?)"},
+				{NodeType: "Search", Relation: "q", Index: "automatic index", Detail: "SEARCH TABLE q USING AUTOMATIC PARTIAL COVERING INDEX (user_id=?)"},
+			},
+		},
+		{
+			// SELECT * FROM users WHERE id IN (SELECT user_id FROM orders)
+			"nested steps",
+			[]map[string]any{
+				step(2, 0, "SEARCH users USING INTEGER PRIMARY KEY (rowid=?)"),
+				step(6, 0, "LIST SUBQUERY 1"),
+				step(8, 6, "SCAN orders"),
+			},
+			[]types.PlanNode{
+				{NodeType: "Search", Relation: "users", Index: "INTEGER PRIMARY KEY", Detail: "SEARCH users USING INTEGER PRIMARY KEY (rowid=?)"},
+				{NodeType: "LIST SUBQUERY 1", Children: []types.PlanNode{
+					{NodeType: "Scan", Relation: "orders", Detail: "SCAN orders"},
+				}},
+			},
+		},
+		{
+			"no table",
+			[]map[string]any{
+				step(1, 0, "SCAN CONSTANT ROW"),
+				step(2, 0, "SCAN SUBQUERY 1"),
+			},
+			[]types.PlanNode{
+				{NodeType: "Scan", Detail: "SCAN CONSTANT ROW"},
+				{NodeType: "Scan", Detail: "SCAN SUBQUERY 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := parsePlan(tt.rows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Dialect != types.DialectSQLite || plan.TotalCost != nil || plan.EstimatedRows != nil {
+				t.Errorf("parsePlan() = %+v, want a SQLite plan without estimates", plan)
+			}
+			if !reflect.DeepEqual(plan.Nodes, tt.nodes) {
+				t.Errorf("parsePlan() nodes = %+v, want %+v", plan.Nodes, tt.nodes)
+			}
+		})
+	}
+}
+
+func TestParsePlanErrors(t *testing.T) {
+	rows := []map[string]any{{"id": "1", "parent": int64(0), "detail": "SCAN t"}}
+	if _, err := parsePlan(rows); err == nil {
+		t.Error("parsePlan() error = nil, want an error")
+	}
+}
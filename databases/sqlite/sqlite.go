@@ -3,30 +3,36 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/melkeydev/mcp-database/databases/catalog"
 	"github.com/melkeydev/mcp-database/types"
 )
 
 type SQLiteConnector struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	opts  catalog.Options
+	cache *catalog.Cache
 }
 
-func NewSQLiteConnector(connectionString string) (*SQLiteConnector, error) {
+func NewSQLiteConnector(ctx context.Context, connectionString string, opts catalog.Options) (*SQLiteConnector, error) {
 	db, err := sqlx.Open("sqlite3", connectionString)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	connector := &SQLiteConnector{
-		db: db,
+		db:    db,
+		opts:  opts,
+		cache: catalog.NewCache(opts.CacheTTL),
 	}
 
 	// Test the connection
-	if err := connector.Ping(context.Background()); err != nil {
+	if err := connector.Ping(ctx); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -38,8 +44,12 @@ func (c *SQLiteConnector) Ping(ctx context.Context) error {
 	return c.db.PingContext(ctx)
 }
 
+func (c *SQLiteConnector) Dialect() types.Dialect {
+	return types.DialectSQLite
+}
+
 // Discover
-func (c *SQLiteConnector) Scan(ctx context.Context, tablesList []string) ([]types.Table, error) {
+func (c *SQLiteConnector) Scan(ctx context.Context, tablesList []string) (*types.ScanResult, error) {
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
@@ -48,65 +58,44 @@ func (c *SQLiteConnector) Scan(ctx context.Context, tablesList []string) ([]type
 	}
 	defer tx.Commit()
 
-	var query string
-	var args []interface{}
+	all, err := c.listTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
 
+	matched := catalog.MatchResult{Tables: all}
 	if len(tablesList) > 0 {
-		// Query specific tables
-		placeholders := make([]string, len(tablesList))
-		args = make([]interface{}, len(tablesList))
-
-		for i, table := range tablesList {
-			placeholders[i] = "?"
-			args[i] = table
-		}
-
-		query = fmt.Sprintf(`
-			SELECT name 
-			FROM sqlite_master 
-			WHERE type='table' 
-			AND name NOT LIKE 'sqlite_%%'
-			AND name IN (%s)
-		`, strings.Join(placeholders, ","))
-
-	} else {
-		query = `
-			SELECT name 
-			FROM sqlite_master 
-			WHERE type='table' 
-			AND name NOT LIKE 'sqlite_%'
-		`
+		matched = catalog.Match(types.DialectSQLite, tablesList, all, "main")
 	}
 
-	rows, err := tx.QueryContext(ctx, query, args...)
+	result := &types.ScanResult{
+		Tables:      []types.Table{},
+		NotFound:    matched.NotFound,
+		Suggestions: matched.Suggestions,
+	}
+	columns, err := c.loadTableColumns(ctx, tx, matched.Tables)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query tables: %w", err)
+		return nil, fmt.Errorf("failed to load columns: %w", err)
 	}
-	defer rows.Close()
-
-	var tables []types.Table
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			return nil, fmt.Errorf("failed to scan table: %w", err)
-		}
-
-		columns, err := c.loadColumns(ctx, tx, tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load columns for table %s: %w", tableName, err)
-		}
-
-		tables = append(tables, types.Table{
-			Name:    tableName,
-			Columns: columns,
+	for i, ref := range matched.Tables {
+		result.Tables = append(result.Tables, types.Table{
+			Name:    ref.Quote(types.DialectSQLite),
+			Kind:    ref.Kind(),
+			Columns: columns[i],
 		})
 	}
 
-	return tables, nil
+	return result, nil
 }
 
 // Query
-func (c *SQLiteConnector) Query(ctx context.Context, sqlQuery string) ([]map[string]any, error) {
+func (c *SQLiteConnector) Query(ctx context.Context, sqlQuery string, opts types.QueryOptions) (*types.QueryResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
@@ -115,32 +104,79 @@ func (c *SQLiteConnector) Query(ctx context.Context, sqlQuery string) ([]map[str
 	}
 	defer tx.Commit()
 
-	rows, err := tx.QueryxContext(ctx, sqlQuery)
+	queryCtx, cancelQuery := context.WithCancel(ctx)
+	defer cancelQuery()
+
+	rows, err := tx.QueryxContext(queryCtx, sqlQuery)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query db: %w", err)
 	}
 	defer rows.Close()
 
-	var results []map[string]any
-	for rows.Next() {
-		row := make(map[string]any)
-		if err := rows.MapScan(row); err != nil {
-			return nil, fmt.Errorf("unable to scan row: %w", err)
-		}
-		results = append(results, row)
-	}
+	return catalog.ReadRows(rows, opts, cancelQuery)
+}
 
-	return results, nil
+// Explain returns the planner's plan for a query without running it
+func (c *SQLiteConnector) Explain(ctx context.Context, sqlQuery string, timeout time.Duration) (*types.QueryPlan, error) {
+	result, err := c.Query(ctx, "EXPLAIN QUERY PLAN "+sqlQuery, types.QueryOptions{Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+	return parsePlan(result.Rows)
 }
 
 // Sample
-func (c *SQLiteConnector) Sample(ctx context.Context, table string, limit int) ([]map[string]any, error) {
-	if limit <= 0 {
-		limit = 10
+func (c *SQLiteConnector) Sample(ctx context.Context, table string, opts types.SampleOptions) (catalog.TableRef, []map[string]any, error) {
+	ref, err := c.resolveTable(ctx, c.db, table)
+	if err != nil {
+		return catalog.TableRef{}, nil, err
 	}
 
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d", table, limit)
-	return c.Query(ctx, query)
+	rows, err := c.sampleTable(ctx, ref, opts)
+	return ref, rows, err
+}
+
+func (c *SQLiteConnector) sampleTable(ctx context.Context, ref catalog.TableRef, opts types.SampleOptions) ([]map[string]any, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	columns, err := c.loadColumns(ctx, c.db, ref.Name, ref.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load columns: %w", err)
+	}
+
+	if opts.Where != "" {
+		check := fmt.Sprintf("SELECT 1 FROM %s WHERE (%s\n)", ref.Quote(types.DialectSQLite), opts.Where)
+		if err := c.CheckAccess(ctx, check); err != nil {
+			return nil, fmt.Errorf("invalid where clause: %w", err)
+		}
+	}
+
+	sample := catalog.SampleQuery{
+		Dialect:     types.DialectSQLite,
+		Table:       ref,
+		Columns:     columns,
+		ListColumns: !c.opts.Access.IsZero(),
+	}
+
+	query, err := sample.Build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnQuery != nil {
+		opts.OnQuery(query)
+	}
+
+	result, err := c.Query(ctx, query, types.QueryOptions{
+		MaxRows:  opts.Limit,
+		MaxBytes: opts.MaxBytes,
+		Timeout:  opts.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Rows, nil
 }
 
 func (c *SQLiteConnector) Close() error {
@@ -150,89 +186,509 @@ func (c *SQLiteConnector) Close() error {
 	return nil
 }
 
-func (c *SQLiteConnector) loadColumns(ctx context.Context, tx *sqlx.Tx, tableName string) ([]types.Column, error) {
-	query := fmt.Sprintf("PRAGMA table_info('%s')", tableName)
+// listDatabases returns the attached databases allowed by the schema filter.
+func (c *SQLiteConnector) listDatabases(ctx context.Context, q sqlx.QueryerContext) ([]string, error) {
+	return c.cache.Schemas(func() ([]string, error) {
+		var all []string
+		if err := sqlx.SelectContext(ctx, q, &all, `SELECT name FROM pragma_database_list ORDER BY seq`); err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+
+		var names []string
+		for _, name := range all {
+			if c.opts.Schemas.Allows(types.DialectSQLite, name) {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	})
+}
+
+// listTables returns every table and view in the visible attached databases.
+func (c *SQLiteConnector) listTables(ctx context.Context, q sqlx.QueryerContext) ([]catalog.TableRef, error) {
+	return c.cache.Tables(func() ([]catalog.TableRef, error) {
+		databases, err := c.listDatabases(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		var tables []catalog.TableRef
+		for _, database := range databases {
+			var refs []catalog.TableRef
+			err := sqlx.SelectContext(ctx, q, &refs, fmt.Sprintf(`
+				SELECT
+					? AS table_schema,
+					name AS table_name,
+					CASE type WHEN 'view' THEN 'VIEW' ELSE 'BASE TABLE' END AS table_type
+				FROM %s.sqlite_master
+				WHERE type IN ('table', 'view')
+				AND name NOT LIKE 'sqlite_%%'
+				ORDER BY name
+			`, catalog.QuoteIdent(types.DialectSQLite, database)), database)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tables: %w", err)
+			}
+			for _, ref := range refs {
+				if c.opts.Access.TableVisible(ref) {
+					tables = append(tables, ref)
+				}
+			}
+		}
+		return tables, nil
+	})
+}
+
+// RefreshSchema drops cached catalog entries for the named tables, or for
+// every table when none are named
+func (c *SQLiteConnector) RefreshSchema(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	return c.cache.Refresh(tables, func(name string) (catalog.TableRef, error) {
+		return c.resolveTable(ctx, c.db, name)
+	})
+}
 
-	rows, err := tx.QueryContext(ctx, query)
+// CheckAccess rejects a query that references a hidden table or column
+func (c *SQLiteConnector) CheckAccess(ctx context.Context, query string) error {
+	return c.opts.Access.CheckQuery(types.DialectSQLite, query, catalog.AccessLookup{
+		Resolve: func(name string) (catalog.TableRef, error) {
+			return c.resolveTable(ctx, c.db, name)
+		},
+		Columns: func(ref catalog.TableRef) ([]types.Column, error) {
+			columns, err := c.loadAllColumns(ctx, c.db, []catalog.TableRef{ref})
+			if err != nil {
+				return nil, err
+			}
+			return columns[0], nil
+		},
+	})
+}
+
+// ListTables returns every visible table and view
+func (c *SQLiteConnector) ListTables(ctx context.Context) ([]catalog.TableRef, error) {
+	return c.listTables(ctx, c.db)
+}
+
+// ListSchemas returns the attached databases allowed by the schema filter
+func (c *SQLiteConnector) ListSchemas(ctx context.Context) ([]types.Schema, error) {
+	names, err := c.listDatabases(ctx, c.db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query columns: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	var columns []types.Column
-	for rows.Next() {
-		var cid int
-		var name, dataType string
-		var notNull int
-		var defaultValue *string
-		var pk int
+	tables, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
-			return nil, fmt.Errorf("failed to scan column: %w", err)
-		}
+	return catalog.Schemas(names, tables, "main"), nil
+}
 
-		columns = append(columns, types.Column{
-			Name:     name,
-			Type:     dataType,
-			Nullable: notNull == 0,
-		})
+// resolveTable maps a caller supplied table name onto a catalog entry.
+func (c *SQLiteConnector) resolveTable(ctx context.Context, q sqlx.QueryerContext, table string) (catalog.TableRef, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return catalog.TableRef{}, err
 	}
 
+	return catalog.Resolve(types.DialectSQLite, table, tables, "main")
+}
+
+// loadColumns returns the columns of a single table.
+func (c *SQLiteConnector) loadColumns(ctx context.Context, q sqlx.QueryerContext, tableName, tableSchema string) ([]types.Column, error) {
+	columns, err := c.loadTableColumns(ctx, q, []catalog.TableRef{{Schema: tableSchema, Name: tableName}})
+	if err != nil {
+		return nil, err
+	}
+	return columns[0], nil
+}
+
+// loadTableColumns returns the visible columns of every table in refs.
+func (c *SQLiteConnector) loadTableColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	columns, err := c.loadAllColumns(ctx, q, refs)
+	if err != nil {
+		return nil, err
+	}
+	for i, ref := range refs {
+		columns[i] = c.opts.Access.VisibleColumns(ref, columns[i])
+	}
 	return columns, nil
 }
 
-func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string) (*types.TableDescription, error) {
+// loadAllColumns returns the columns of every table in refs, hidden or not,
+// reading the uncached ones with a single pragma_table_info join per
+// attached database.
+func (c *SQLiteConnector) loadAllColumns(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) ([][]types.Column, error) {
+	return c.cache.Columns(refs, func(missing []catalog.TableRef, add func(schema, name string, column types.Column)) error {
+		var databases []string
+		names := make(map[string][]string)
+		for _, ref := range missing {
+			if _, ok := names[ref.Schema]; !ok {
+				databases = append(databases, ref.Schema)
+			}
+			names[ref.Schema] = append(names[ref.Schema], ref.Name)
+		}
+
+		for _, database := range databases {
+			tableNames, err := json.Marshal(names[database])
+			if err != nil {
+				return err
+			}
+
+			rows, err := q.QueryContext(ctx, fmt.Sprintf(`
+				SELECT m.name, p.name, p.type, p."notnull"
+				FROM %s.sqlite_master AS m
+				JOIN pragma_table_info(m.name, ?) AS p
+				WHERE m.type IN ('table', 'view')
+				AND m.name IN (SELECT value FROM json_each(?))
+				ORDER BY m.name, p.cid
+			`, catalog.QuoteIdent(types.DialectSQLite, database)), database, string(tableNames))
+			if err != nil {
+				return fmt.Errorf("failed to query columns: %w", err)
+			}
+
+			for rows.Next() {
+				var table, name, dataType string
+				var notNull int
+				if err := rows.Scan(&table, &name, &dataType, &notNull); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan column: %w", err)
+				}
+
+				add(database, table, types.Column{
+					Name:     name,
+					Type:     dataType,
+					Nullable: notNull == 0,
+				})
+			}
+			err = rows.Err()
+			rows.Close()
+			if err != nil {
+				return fmt.Errorf("failed to read columns: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Relationships returns the declared and inferred relationships involving
+// the named tables, or every relationship when none are named.
+func (c *SQLiteConnector) Relationships(ctx context.Context, tables []string) ([]types.Relationship, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Relationships(types.DialectSQLite, keys, refs), nil
+}
+
+// JoinPaths suggests how to join the named tables through their
+// relationships, best first.
+func (c *SQLiteConnector) JoinPaths(ctx context.Context, tables []string, opts types.JoinPathOptions) ([]types.JoinPath, error) {
+	refs, err := c.resolveTables(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.JoinPaths(types.DialectSQLite, keys, refs, opts)
+}
+
+// SchemaGraph returns the tables selected by opts with their columns,
+// primary keys and relationships, for drawing a diagram.
+func (c *SQLiteConnector) SchemaGraph(ctx context.Context, opts types.DiagramOptions) (*types.SchemaGraph, error) {
+	only, err := c.resolveTables(ctx, opts.Tables)
+	if err != nil {
+		return nil, err
+	}
+	var focus *catalog.TableRef
+	if opts.Focus != "" {
+		ref, err := c.resolveTable(ctx, c.db, opts.Focus)
+		if err != nil {
+			return nil, err
+		}
+		focus = &ref
+	}
+
+	all, err := c.listTables(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := c.loadKeys(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := catalog.DiagramTables(all, keys, only, focus, opts.Hops, opts.IncludeInferred)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.loadTableColumns(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, err := c.loadPrimaryKeys(ctx, c.db, tables)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.SchemaGraph(types.DialectSQLite, tables, columns, primaryKeys, keys, opts.IncludeInferred), nil
+}
+
+// loadPrimaryKeys returns the primary key columns of the tables in refs,
+// keyed by schema and name.
+func (c *SQLiteConnector) loadPrimaryKeys(ctx context.Context, q sqlx.QueryerContext, refs []catalog.TableRef) (map[catalog.TableRef][]string, error) {
+	var databases []string
+	names := make(map[string][]string)
+	for _, ref := range refs {
+		if _, ok := names[ref.Schema]; !ok {
+			databases = append(databases, ref.Schema)
+		}
+		names[ref.Schema] = append(names[ref.Schema], ref.Name)
+	}
+
+	primaryKeys := make(map[catalog.TableRef][]string)
+	for _, database := range databases {
+		tableNames, err := json.Marshal(names[database])
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := q.QueryContext(ctx, fmt.Sprintf(`
+			SELECT m.name, p.name
+			FROM %s.sqlite_master AS m
+			JOIN pragma_table_info(m.name, ?) AS p
+			WHERE m.type = 'table'
+			AND p.pk > 0
+			AND m.name IN (SELECT value FROM json_each(?))
+			ORDER BY m.name, p.pk
+		`, catalog.QuoteIdent(types.DialectSQLite, database)), database, string(tableNames))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query primary keys: %w", err)
+		}
+
+		for rows.Next() {
+			var table, column string
+			if err := rows.Scan(&table, &column); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan primary key: %w", err)
+			}
+			ref := catalog.TableRef{Schema: database, Name: table}
+			primaryKeys[ref] = append(primaryKeys[ref], column)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read primary keys: %w", err)
+		}
+	}
+	return primaryKeys, nil
+}
+
+// ListObjects returns the views, materialized views, functions,
+// procedures and triggers of the given kinds, or of every kind when none
+// are given, without their definitions.
+func (c *SQLiteConnector) ListObjects(ctx context.Context, kinds []string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.FilterObjects(objects, kinds)
+}
+
+// DescribeObject returns the objects called name, optionally only those of
+// kind, with their definitions.
+func (c *SQLiteConnector) DescribeObject(ctx context.Context, name, kind string) ([]types.SchemaObject, error) {
+	objects, err := c.loadObjects(ctx, c.db)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.FindObjects(types.DialectSQLite, objects, name, kind, "main")
+}
+
+// loadObjects returns the views and triggers of every visible attached
+// database with their CREATE statements. SQLite has no materialized views
+// or stored routines.
+func (c *SQLiteConnector) loadObjects(ctx context.Context, q sqlx.QueryerContext) ([]types.SchemaObject, error) {
+	databases, err := c.listDatabases(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []types.SchemaObject
+	for _, database := range databases {
+		var found []types.SchemaObject
+		err := sqlx.SelectContext(ctx, q, &found, fmt.Sprintf(`
+			SELECT
+				?1 AS object_schema,
+				name AS object_name,
+				type AS object_kind,
+				CASE type WHEN 'trigger' THEN tbl_name ELSE '' END AS table_name,
+				'' AS signature,
+				'' AS language,
+				COALESCE(sql, '') AS definition
+			FROM %s.sqlite_master
+			WHERE type IN ('view', 'trigger')
+			ORDER BY type DESC, name
+		`, catalog.QuoteIdent(types.DialectSQLite, database)), database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list views and triggers: %w", err)
+		}
+		objects = append(objects, found...)
+	}
+	return catalog.VisibleObjects(objects, tables), nil
+}
+
+// resolveTables maps each of the caller supplied table names onto a
+// catalog entry.
+func (c *SQLiteConnector) resolveTables(ctx context.Context, tables []string) ([]catalog.TableRef, error) {
+	refs := make([]catalog.TableRef, 0, len(tables))
+	for _, name := range tables {
+		ref, err := c.resolveTable(ctx, c.db, name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// loadKeys returns the visible declared foreign keys followed by the
+// inferred ones.
+func (c *SQLiteConnector) loadKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	tables, err := c.listTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := c.cache.ForeignKeys(func() ([]catalog.ForeignKey, error) {
+		return c.loadForeignKeys(ctx, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := c.loadTableColumns(ctx, q, tables)
+	if err != nil {
+		return nil, err
+	}
+	return c.opts.Access.Keys(tables, columns, declared), nil
+}
+
+// loadForeignKeys reads the foreign keys of every visible attached
+// database from pragma_foreign_key_list. Keys that name no columns on the
+// referenced side point at its primary key; referenced table names are
+// matched case-insensitively, as SQLite does.
+func (c *SQLiteConnector) loadForeignKeys(ctx context.Context, q sqlx.QueryerContext) ([]catalog.ForeignKey, error) {
+	databases, err := c.listDatabases(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []catalog.ForeignKey
+	for _, database := range databases {
+		var columns []catalog.KeyColumn
+		err := sqlx.SelectContext(ctx, q, &columns, fmt.Sprintf(`
+			SELECT
+				p.id AS constraint_id,
+				'' AS constraint_name,
+				?1 AS table_schema,
+				m.name AS table_name,
+				p."from" AS column_name,
+				?1 AS referenced_table_schema,
+				COALESCE(r.name, p."table") AS referenced_table_name,
+				COALESCE(p."to", k.name, '') AS referenced_column_name,
+				p.on_update AS update_rule,
+				p.on_delete AS delete_rule
+			FROM %[1]s.sqlite_master AS m
+			JOIN pragma_foreign_key_list(m.name, ?1) AS p
+			LEFT JOIN %[1]s.sqlite_master AS r ON r.type = 'table' AND r.name = p."table" COLLATE NOCASE
+			LEFT JOIN pragma_table_info(r.name, ?1) AS k ON p."to" IS NULL AND k.pk = p.seq + 1
+			WHERE m.type = 'table'
+			ORDER BY m.name, p.id, p.seq
+		`, catalog.QuoteIdent(types.DialectSQLite, database)), database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+		}
+		keys = append(keys, catalog.GroupKeys(columns)...)
+	}
+	return keys, nil
+}
+
+// countRows counts the rows of ref, giving up after timeout.
+func (c *SQLiteConnector) countRows(ctx context.Context, ref catalog.TableRef, timeout time.Duration) (int64, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var count int64
+	err := c.db.GetContext(ctx, &count, fmt.Sprintf("SELECT COUNT(*) FROM %s", ref.Quote(types.DialectSQLite)))
+	return count, err
+}
+
+func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string, opts types.DescribeOptions) (catalog.TableRef, *types.TableDescription, error) {
 	tx, err := c.db.BeginTxx(ctx, &sql.TxOptions{
 		ReadOnly: true,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Commit()
 
-	// Check if table exists
-	var exists bool
-	err = tx.GetContext(ctx, &exists, `
-		SELECT EXISTS (
-			SELECT 1 FROM sqlite_master 
-			WHERE type='table' AND name = ?
-		)`, table)
+	// Resolve the requested name against the catalog
+	ref, err := c.resolveTable(ctx, tx, table)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check table existence: %w", err)
-	}
-	if !exists {
-		return nil, fmt.Errorf("table %s not found", table)
+		return catalog.TableRef{}, nil, err
 	}
+	table = ref.Name
 
 	// Get columns
-	columns, err := c.loadColumns(ctx, tx, table)
+	columns, err := c.loadColumns(ctx, tx, table, ref.Schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load columns: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load columns: %w", err)
 	}
 
 	// Get row count
 	var rowCount int64
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
-	err = tx.GetContext(ctx, &rowCount, countQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get row count: %w", err)
+	if !opts.SkipRowCount {
+		rowCount, err = c.countRows(ctx, ref, opts.CountTimeout)
+		if err != nil {
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to get row count: %w", err)
+		}
 	}
 
 	// Get sample data
-	sampleData, err := c.Sample(ctx, table, 5)
-	if err != nil {
-		// Non-critical error, continue without sample data
-		sampleData = nil
+	var sampleData []map[string]any
+	if !opts.SkipSampleData {
+		sampleData, err = c.sampleTable(ctx, ref, types.SampleOptions{
+			Limit:    5,
+			MaxBytes: opts.SampleMaxBytes,
+			Timeout:  opts.SampleTimeout,
+		})
+		if err != nil {
+			// Non-critical error, continue without sample data
+			sampleData = nil
+		}
 	}
 
 	// Get primary keys from table_info
 	pkRows, err := tx.QueryContext(ctx, `
 		SELECT name 
-		FROM pragma_table_info(?)
+		FROM pragma_table_info(?, ?)
 		WHERE pk > 0
-		ORDER BY pk`, table)
+		ORDER BY pk`, table, ref.Schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get primary keys: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get primary keys: %w", err)
 	}
 	defer pkRows.Close()
 
@@ -240,7 +696,7 @@ func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string) (*typ
 	for pkRows.Next() {
 		var pkColumn string
 		if err := pkRows.Scan(&pkColumn); err != nil {
-			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan primary key: %w", err)
 		}
 		primaryKeys = append(primaryKeys, pkColumn)
 	}
@@ -248,10 +704,10 @@ func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string) (*typ
 	// Get indexes
 	indexRows, err := tx.QueryContext(ctx, `
 		SELECT name, "unique"
-		FROM pragma_index_list(?)
-		WHERE origin != 'pk'`, table)
+		FROM pragma_index_list(?, ?)
+		WHERE origin != 'pk'`, table, ref.Schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get indexes: %w", err)
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to get indexes: %w", err)
 	}
 	defer indexRows.Close()
 
@@ -260,14 +716,14 @@ func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string) (*typ
 		var indexName string
 		var isUnique bool
 		if err := indexRows.Scan(&indexName, &isUnique); err != nil {
-			return nil, fmt.Errorf("failed to scan index: %w", err)
+			return catalog.TableRef{}, nil, fmt.Errorf("failed to scan index: %w", err)
 		}
 
 		// Get columns for this index
 		colRows, err := tx.QueryContext(ctx, `
 			SELECT name 
-			FROM pragma_index_info(?)
-			ORDER BY seqno`, indexName)
+			FROM pragma_index_info(?, ?)
+			ORDER BY seqno`, indexName, ref.Schema)
 		if err != nil {
 			continue // Skip this index if we can't get its columns
 		}
@@ -291,12 +747,21 @@ func (c *SQLiteConnector) DescribeTable(ctx context.Context, table string) (*typ
 		}
 	}
 
-	return &types.TableDescription{
-		Name:        table,
+	description := &types.TableDescription{
+		Name:        ref.Quote(types.DialectSQLite),
 		Columns:     columns,
 		RowCount:    rowCount,
 		SampleData:  sampleData,
 		PrimaryKeys: primaryKeys,
 		Indexes:     indexes,
-	}, nil
+	}
+	c.opts.Access.FilterDescription(ref, description)
+
+	keys, err := c.loadKeys(ctx, tx)
+	if err != nil {
+		return catalog.TableRef{}, nil, fmt.Errorf("failed to load relationships: %w", err)
+	}
+	catalog.DescribeRelationships(types.DialectSQLite, keys, ref, description)
+
+	return ref, description, nil
 }
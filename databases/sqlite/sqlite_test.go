@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/databases/catalog"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// BenchmarkScan scans every table of a generated schema with the cache
+// disabled, so each iteration reads the catalog.
+func BenchmarkScan(b *testing.B) {
+	for _, tables := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			connector := generatedSchema(b, tables, 8)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result, err := connector.Scan(ctx, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(result.Tables) != tables {
+					b.Fatalf("scanned %d tables, want %d", len(result.Tables), tables)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkScanPerTable is the baseline for BenchmarkScan: the same schema
+// read the way Scan did before columns were loaded in bulk, with one
+// PRAGMA table_info query per table inside the scan's transaction.
+func BenchmarkScanPerTable(b *testing.B) {
+	for _, tables := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			connector := generatedSchema(b, tables, 8)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result, err := scanPerTable(ctx, connector)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(result) != tables {
+					b.Fatalf("scanned %d tables, want %d", len(result), tables)
+				}
+			}
+		})
+	}
+}
+
+func scanPerTable(ctx context.Context, connector *SQLiteConnector) ([]types.Table, error) {
+	tx, err := connector.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit()
+
+	refs, err := connector.listTables(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []types.Table
+	for _, ref := range refs {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.table_info(%s)",
+			catalog.QuoteIdent(types.DialectSQLite, ref.Schema), catalog.QuoteIdent(types.DialectSQLite, ref.Name)))
+		if err != nil {
+			return nil, err
+		}
+
+		var columns []types.Column
+		for rows.Next() {
+			var cid, notNull, pk int
+			var name, dataType string
+			var defaultValue *string
+			if err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			columns = append(columns, types.Column{Name: name, Type: dataType, Nullable: notNull == 0})
+		}
+		rows.Close()
+
+		tables = append(tables, types.Table{Name: ref.Quote(types.DialectSQLite), Kind: ref.Kind(), Columns: columns})
+	}
+	return tables, nil
+}
+
+// generatedSchema opens a database file holding tables tables of columns
+// columns each.
+func generatedSchema(b *testing.B, tables, columns int) *SQLiteConnector {
+	b.Helper()
+	path := filepath.Join(b.TempDir(), "schema.db")
+	connector, err := NewSQLiteConnector(context.Background(), path, catalog.Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { connector.Close() })
+
+	definitions := []string{"id INTEGER PRIMARY KEY"}
+	for i := 1; i < columns; i++ {
+		definitions = append(definitions, fmt.Sprintf("column_%d TEXT", i))
+	}
+
+	tx, err := connector.db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < tables; i++ {
+		if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE table_%d (%s)", i, strings.Join(definitions, ", "))); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return connector
+}
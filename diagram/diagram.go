@@ -0,0 +1,190 @@
+// Package diagram renders a schema as an entity-relationship diagram in
+// Mermaid erDiagram or Graphviz DOT syntax, for pasting into documents.
+//
+// Inferred relationships are drawn dashed. Mermaid only accepts simple
+// names for entities, attributes and types, so other characters are
+// replaced by underscores there; the original name is kept as the
+// attribute comment.
+package diagram
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// Format names a diagram syntax.
+type Format string
+
+const (
+	FormatMermaid Format = "mermaid"
+	FormatDOT     Format = "dot"
+)
+
+// Render draws graph in format.
+func Render(format Format, graph *types.SchemaGraph) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(graph), nil
+	case FormatDOT:
+		return DOT(graph), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format %q, use %s or %s", format, FormatMermaid, FormatDOT)
+	}
+}
+
+var (
+	mermaidName = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+	mermaidType = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]`)
+)
+
+// Mermaid draws graph as a Mermaid erDiagram.
+func Mermaid(graph *types.SchemaGraph) string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+
+	for _, table := range graph.Tables {
+		foreign := foreignColumns(graph, table.Name)
+		fmt.Fprintf(&b, "    %s {\n", mermaidIdent(table.Name))
+		for _, column := range table.Columns {
+			dataType := mermaidType.ReplaceAllString(column.Type, "_")
+			if dataType == "" {
+				dataType = "unknown"
+			}
+			line := fmt.Sprintf("        %s %s", dataType, mermaidIdent(column.Name))
+
+			var keys []string
+			if contains(table.PrimaryKeys, column.Name) {
+				keys = append(keys, "PK")
+			}
+			if foreign[column.Name] {
+				keys = append(keys, "FK")
+			}
+			if len(keys) > 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			if mermaidIdent(column.Name) != column.Name {
+				line += fmt.Sprintf(" %q", strings.ReplaceAll(column.Name, `"`, "'"))
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, r := range graph.Relationships {
+		// Many rows refer to at most one; a nullable key may refer to none
+		target := "||"
+		if nullable(graph, r) {
+			target = "o|"
+		}
+		line := "--"
+		if r.Inferred {
+			line = ".."
+		}
+		fmt.Fprintf(&b, "    %s }o%s%s %s : %q\n",
+			mermaidIdent(r.Table), line, target, mermaidIdent(r.ReferencedTable),
+			strings.ReplaceAll(strings.Join(r.Columns, ", "), `"`, "'"))
+	}
+	return b.String()
+}
+
+func mermaidIdent(name string) string {
+	name = mermaidName.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || name[0] == '-' {
+		name = "_" + name
+	}
+	return name
+}
+
+// DOT draws graph as a Graphviz digraph with one HTML-like table per node
+// and an edge from each foreign key column to the column it references.
+func DOT(graph *types.SchemaGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph schema {\n")
+	b.WriteString("    rankdir=LR;\n")
+	b.WriteString("    node [shape=plaintext, fontname=\"Helvetica\"];\n")
+	b.WriteString("    edge [arrowhead=normal, arrowtail=crow, dir=both];\n\n")
+
+	for _, table := range graph.Tables {
+		foreign := foreignColumns(graph, table.Name)
+		fmt.Fprintf(&b, "    %s [label=<\n", dotString(table.Name))
+		b.WriteString("        <table border=\"0\" cellborder=\"1\" cellspacing=\"0\" cellpadding=\"4\">\n")
+		fmt.Fprintf(&b, "        <tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", html.EscapeString(table.Name))
+		for _, column := range table.Columns {
+			label := html.EscapeString(column.Name)
+			if contains(table.PrimaryKeys, column.Name) {
+				label = "<u>" + label + "</u>"
+			}
+			label += " : " + html.EscapeString(column.Type)
+			if foreign[column.Name] {
+				label += " (FK)"
+			}
+			fmt.Fprintf(&b, "        <tr><td port=\"%s\" align=\"left\">%s</td></tr>\n", html.EscapeString(column.Name), label)
+		}
+		b.WriteString("        </table>>];\n")
+	}
+
+	if len(graph.Relationships) > 0 {
+		b.WriteString("\n")
+	}
+	for _, r := range graph.Relationships {
+		var attrs []string
+		if len(r.Columns) > 1 {
+			attrs = append(attrs, "label="+dotString(strings.Join(r.Columns, ", ")))
+		}
+		if r.Inferred {
+			attrs = append(attrs, "style=dashed")
+		}
+		edge := fmt.Sprintf("    %s:%s -> %s:%s", dotString(r.Table), dotString(r.Columns[0]), dotString(r.ReferencedTable), dotString(r.ReferencedColumns[0]))
+		if len(attrs) > 0 {
+			edge += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		b.WriteString(edge + ";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// foreignColumns returns the columns of table that refer to another table.
+func foreignColumns(graph *types.SchemaGraph, table string) map[string]bool {
+	columns := make(map[string]bool)
+	for _, r := range graph.Relationships {
+		if r.Table == table {
+			for _, name := range r.Columns {
+				columns[name] = true
+			}
+		}
+	}
+	return columns
+}
+
+// nullable reports whether any referencing column of r accepts NULL.
+func nullable(graph *types.SchemaGraph, r types.Relationship) bool {
+	for _, table := range graph.Tables {
+		if table.Name != r.Table {
+			continue
+		}
+		for _, column := range table.Columns {
+			if column.Nullable && contains(r.Columns, column.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,429 @@
+// Package guard classifies SQL text before it reaches a database connector
+// and rejects anything that is not a pure read.
+//
+// Read-only transactions are not enough on their own: the SQLite driver
+// ignores sql.TxOptions.ReadOnly, and PostgreSQL happily runs functions with
+// side effects such as nextval or pg_terminate_backend inside a read-only
+// transaction. The guard works on tokens rather than substrings, so keywords
+// inside string literals, quoted identifiers and comments are ignored.
+package guard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// Rule names the check that rejected a query.
+type Rule string
+
+const (
+	RuleEmpty               Rule = "empty-query"
+	RuleSyntax              Rule = "syntax"
+	RuleMultipleStatements  Rule = "multiple-statements"
+	RuleStatementType       Rule = "statement-type"
+	RuleDataModifyingCTE    Rule = "data-modifying-cte"
+	RuleDataModifyingClause Rule = "data-modifying-clause"
+	RuleSelectInto          Rule = "select-into"
+	RuleLockingClause       Rule = "locking-clause"
+	RuleCopy                Rule = "copy"
+	RulePragmaWrite         Rule = "pragma-write"
+	RuleExplainAnalyze      Rule = "explain-analyze"
+	RuleUnsafeFunction      Rule = "unsafe-function"
+)
+
+// Violation is returned when a query fails one of the guard's rules.
+type Violation struct {
+	Rule Rule
+	// Statement is the 1-based position of the offending statement, or 0
+	// when the violation concerns the query as a whole
+	Statement int
+	Detail    string
+}
+
+func (v *Violation) Error() string {
+	if v.Statement > 0 {
+		return fmt.Sprintf("blocked by read-only rule %q (statement %d): %s", v.Rule, v.Statement, v.Detail)
+	}
+	return fmt.Sprintf("blocked by read-only rule %q: %s", v.Rule, v.Detail)
+}
+
+// CheckReadOnly returns a *Violation if query contains anything other than a
+// single read-only statement in the given dialect.
+func CheckReadOnly(dialect types.Dialect, query string) error {
+	tokens, err := lex(dialect, query)
+	if err != nil {
+		return err
+	}
+
+	statements := splitStatements(tokens)
+	if len(statements) == 0 {
+		return &Violation{Rule: RuleEmpty, Detail: "query contains no SQL statement"}
+	}
+
+	for i, stmt := range statements {
+		if err := checkStatement(dialect, stmt); err != nil {
+			v := err.(*Violation)
+			v.Statement = i + 1
+			return v
+		}
+	}
+
+	if len(statements) > 1 {
+		return &Violation{
+			Rule:   RuleMultipleStatements,
+			Detail: fmt.Sprintf("found %d statements, only one statement per query is allowed", len(statements)),
+		}
+	}
+
+	return nil
+}
+
+// IsQuery reports whether query is a single SELECT, WITH, VALUES or TABLE
+// statement, the kind a planner can explain.
+func IsQuery(dialect types.Dialect, query string) bool {
+	tokens, err := lex(dialect, query)
+	if err != nil {
+		return false
+	}
+	statements := splitStatements(tokens)
+	if len(statements) != 1 {
+		return false
+	}
+
+	stmt := statements[0]
+	lead := 0
+	for lead < len(stmt) && stmt[lead].isPunct("(") {
+		lead++
+	}
+	return lead < len(stmt) && stmt[lead].isWord("SELECT", "WITH", "VALUES", "TABLE")
+}
+
+// CheckExpression validates a fragment that will be spliced into a WHERE
+// clause. The fragment must be a self-contained, read-only boolean
+// expression: balanced parentheses and no statement separators.
+func CheckExpression(dialect types.Dialect, expr string) error {
+	tokens, err := lex(dialect, expr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return &Violation{Rule: RuleEmpty, Detail: "expression is empty"}
+	}
+
+	depth := 0
+	for _, t := range tokens {
+		switch {
+		case t.kind == tokenSemicolon:
+			return &Violation{Rule: RuleMultipleStatements, Detail: "expression must not contain ';'"}
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			depth--
+			if depth < 0 {
+				return &Violation{Rule: RuleSyntax, Detail: "unbalanced parentheses in expression"}
+			}
+		}
+	}
+	if depth != 0 {
+		return &Violation{Rule: RuleSyntax, Detail: "unbalanced parentheses in expression"}
+	}
+
+	return CheckReadOnly(dialect, "SELECT 1 WHERE ("+expr+"\n)")
+}
+
+func splitStatements(tokens []token) [][]token {
+	var statements [][]token
+	start := 0
+	for i, t := range tokens {
+		if t.kind != tokenSemicolon {
+			continue
+		}
+		if i > start {
+			statements = append(statements, tokens[start:i])
+		}
+		start = i + 1
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	return statements
+}
+
+func checkStatement(dialect types.Dialect, stmt []token) error {
+	// Parenthesised queries such as "(SELECT 1) UNION (SELECT 2)"
+	lead := 0
+	for lead < len(stmt) && stmt[lead].isPunct("(") {
+		lead++
+	}
+	if lead == len(stmt) {
+		return &Violation{Rule: RuleSyntax, Detail: "statement has no keyword"}
+	}
+
+	first := stmt[lead]
+	if first.kind != tokenWord {
+		return &Violation{Rule: RuleSyntax, Detail: fmt.Sprintf("unexpected %q at start of statement", first.text)}
+	}
+
+	switch first.value {
+	case "SELECT", "VALUES":
+		return checkRead(dialect, stmt, RuleDataModifyingClause)
+	case "WITH":
+		return checkRead(dialect, stmt, RuleDataModifyingCTE)
+	case "TABLE":
+		if dialect == types.DialectPostgres || dialect == types.DialectMySQL {
+			return checkRead(dialect, stmt, RuleDataModifyingClause)
+		}
+	case "SHOW":
+		if dialect == types.DialectPostgres || dialect == types.DialectMySQL {
+			return nil
+		}
+	case "EXPLAIN":
+		return checkExplain(dialect, stmt[lead+1:])
+	case "DESCRIBE", "DESC":
+		if dialect == types.DialectMySQL {
+			return checkExplain(dialect, stmt[lead+1:])
+		}
+	case "PRAGMA":
+		if dialect == types.DialectSQLite {
+			return checkPragma(stmt[lead+1:])
+		}
+	case "COPY":
+		return &Violation{Rule: RuleCopy, Detail: "COPY reads or writes server files and tables and is not allowed"}
+	}
+
+	return &Violation{
+		Rule:   RuleStatementType,
+		Detail: fmt.Sprintf("%s statements are not allowed, only SELECT queries are permitted", first.value),
+	}
+}
+
+// checkRead inspects a SELECT/WITH/VALUES/TABLE statement for clauses that
+// turn it into a write.
+func checkRead(dialect types.Dialect, stmt []token, dmlRule Rule) error {
+	for i := 0; i < len(stmt); i++ {
+		t := stmt[i]
+		if t.kind == tokenQuotedIdent && isFunctionCall(stmt, i) {
+			// "pg_sleep"(1) calls pg_sleep just the same
+			if name := strings.ToLower(t.value); isUnsafeFunction(dialect, name) {
+				return &Violation{Rule: RuleUnsafeFunction, Detail: fmt.Sprintf("function %s has side effects and is not allowed", name)}
+			}
+		}
+		if t.kind != tokenWord {
+			continue
+		}
+
+		switch t.value {
+		case "INSERT", "UPDATE", "DELETE", "MERGE", "TRUNCATE":
+			if isFunctionCall(stmt, i) {
+				break
+			}
+			if dmlRule == RuleDataModifyingCTE {
+				return &Violation{Rule: dmlRule, Detail: fmt.Sprintf("WITH query contains a data-modifying %s", t.value)}
+			}
+			return &Violation{Rule: dmlRule, Detail: fmt.Sprintf("query contains a data-modifying %s", t.value)}
+
+		case "INTO":
+			return &Violation{Rule: RuleSelectInto, Detail: "SELECT ... INTO creates tables, files or variables and is not allowed"}
+
+		case "FOR":
+			if next := nextWord(stmt, i); next == "UPDATE" || next == "SHARE" || next == "NO" || next == "KEY" {
+				return &Violation{Rule: RuleLockingClause, Detail: fmt.Sprintf("row locking clause FOR %s is not allowed", next)}
+			}
+
+		case "LOCK":
+			if nextWord(stmt, i) == "IN" {
+				return &Violation{Rule: RuleLockingClause, Detail: "row locking clause LOCK IN SHARE MODE is not allowed"}
+			}
+		}
+
+		if isFunctionCall(stmt, i) {
+			if name := strings.ToLower(t.text); isUnsafeFunction(dialect, name) {
+				return &Violation{Rule: RuleUnsafeFunction, Detail: fmt.Sprintf("function %s has side effects and is not allowed", name)}
+			}
+		}
+	}
+	return nil
+}
+
+// checkExplain allows EXPLAIN of a read-only statement. EXPLAIN ANALYZE
+// executes the statement, so it is rejected outright.
+func checkExplain(dialect types.Dialect, rest []token) error {
+	for i, t := range rest {
+		if t.isWord("ANALYZE", "ANALYSE") {
+			return &Violation{Rule: RuleExplainAnalyze, Detail: "EXPLAIN ANALYZE executes the statement and is not allowed"}
+		}
+		if t.kind == tokenWord && isStatementStart(t.value) && !isOptionValue(rest, i) {
+			return checkStatement(dialect, rest[i:])
+		}
+	}
+
+	// MySQL's "EXPLAIN tbl" / "DESCRIBE tbl" describe a table
+	if dialect == types.DialectMySQL {
+		return nil
+	}
+	return &Violation{Rule: RuleSyntax, Detail: "EXPLAIN without a statement"}
+}
+
+// readOnlyPragmas lists the SQLite pragmas that only report information.
+// Pragmas such as optimize or wal_checkpoint do work even without an
+// assignment, so anything not listed here is rejected.
+var readOnlyPragmas = map[string]bool{
+	"application_id":    true,
+	"collation_list":    true,
+	"compile_options":   true,
+	"data_version":      true,
+	"database_list":     true,
+	"encoding":          true,
+	"foreign_key_check": true,
+	"foreign_key_list":  true,
+	"foreign_keys":      true,
+	"freelist_count":    true,
+	"function_list":     true,
+	"index_info":        true,
+	"index_list":        true,
+	"index_xinfo":       true,
+	"integrity_check":   true,
+	"journal_mode":      true,
+	"module_list":       true,
+	"page_count":        true,
+	"page_size":         true,
+	"pragma_list":       true,
+	"quick_check":       true,
+	"schema_version":    true,
+	"table_info":        true,
+	"table_list":        true,
+	"table_xinfo":       true,
+	"user_version":      true,
+}
+
+func checkPragma(rest []token) error {
+	var name string
+	for i, t := range rest {
+		if t.isPunct("=") {
+			return &Violation{Rule: RulePragmaWrite, Detail: "PRAGMA assignments are not allowed"}
+		}
+		if name == "" && t.kind == tokenWord && !(i+1 < len(rest) && rest[i+1].isPunct(".")) {
+			name = strings.ToLower(t.text)
+		}
+	}
+
+	if name == "" {
+		return &Violation{Rule: RuleSyntax, Detail: "PRAGMA without a name"}
+	}
+	if !readOnlyPragmas[name] {
+		return &Violation{Rule: RulePragmaWrite, Detail: fmt.Sprintf("PRAGMA %s is not in the list of read-only pragmas", name)}
+	}
+
+	// journal_mode(x) and friends set a value when given an argument; the
+	// table-valued pragmas take their argument the same way.
+	if hasArgument(rest) && !pragmaTakesArgument(name) {
+		return &Violation{Rule: RulePragmaWrite, Detail: fmt.Sprintf("PRAGMA %s with an argument changes the database", name)}
+	}
+	return nil
+}
+
+func pragmaTakesArgument(name string) bool {
+	switch name {
+	case "foreign_key_check", "foreign_key_list", "index_info", "index_list", "index_xinfo",
+		"integrity_check", "quick_check", "table_info", "table_list", "table_xinfo":
+		return true
+	}
+	return false
+}
+
+func hasArgument(rest []token) bool {
+	for _, t := range rest {
+		if t.isPunct("(") {
+			return true
+		}
+	}
+	return false
+}
+
+var unsafeFunctions = map[types.Dialect]map[string]bool{
+	types.DialectPostgres: {
+		"nextval": true, "setval": true, "set_config": true,
+		"pg_terminate_backend": true, "pg_cancel_backend": true,
+		"pg_reload_conf": true, "pg_rotate_logfile": true, "pg_switch_wal": true,
+		"pg_create_restore_point": true, "pg_promote": true, "pg_sleep": true,
+		"pg_sleep_for": true, "pg_sleep_until": true,
+		"pg_read_file": true, "pg_read_binary_file": true, "pg_ls_dir": true, "pg_stat_file": true,
+		"pg_file_write": true, "pg_file_rename": true, "pg_file_unlink": true,
+		"lo_import": true, "lo_export": true, "lo_unlink": true, "lo_create": true,
+		"lo_creat": true, "lo_put": true, "lo_from_bytea": true,
+		"dblink": true, "dblink_exec": true, "dblink_connect": true, "dblink_send_query": true,
+		"pg_notify": true, "txid_current": true, "pg_current_xact_id": true,
+		// These run a query given as a string, which the guard never sees,
+		// or dump whole tables, schemas or databases
+		"query_to_xml": true, "query_to_xmlschema": true, "query_to_xml_and_xmlschema": true,
+		"cursor_to_xml": true, "cursor_to_xmlschema": true, "ts_stat": true, "ts_rewrite": true,
+		"table_to_xml": true, "table_to_xmlschema": true, "table_to_xml_and_xmlschema": true,
+		"schema_to_xml": true, "schema_to_xmlschema": true, "schema_to_xml_and_xmlschema": true,
+		"database_to_xml": true, "database_to_xmlschema": true, "database_to_xml_and_xmlschema": true,
+	},
+	types.DialectMySQL: {
+		"sleep": true, "benchmark": true, "get_lock": true, "release_lock": true,
+		"release_all_locks": true, "load_file": true, "master_pos_wait": true,
+		"source_pos_wait": true,
+	},
+	types.DialectSQLite: {
+		"load_extension": true, "writefile": true, "readfile": true, "edit": true,
+		"fts3_tokenizer": true,
+	},
+}
+
+// unsafeFunctionPrefixes catches families of functions such as the
+// PostgreSQL advisory lock helpers.
+var unsafeFunctionPrefixes = map[types.Dialect][]string{
+	types.DialectPostgres: {"pg_advisory", "pg_try_advisory", "pg_replication_", "pg_drop_replication", "pg_create_"},
+}
+
+func isUnsafeFunction(dialect types.Dialect, name string) bool {
+	if unsafeFunctions[dialect][name] {
+		return true
+	}
+	for _, prefix := range unsafeFunctionPrefixes[dialect] {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFunctionCall reports whether the word at i is immediately followed by
+// an opening parenthesis.
+func isFunctionCall(stmt []token, i int) bool {
+	return i+1 < len(stmt) && stmt[i+1].isPunct("(")
+}
+
+func nextWord(stmt []token, i int) string {
+	if i+1 < len(stmt) && stmt[i+1].kind == tokenWord {
+		return stmt[i+1].value
+	}
+	return ""
+}
+
+func isStatementStart(word string) bool {
+	switch word {
+	case "SELECT", "WITH", "VALUES", "TABLE", "INSERT", "UPDATE", "DELETE", "MERGE",
+		"REPLACE", "CREATE", "DROP", "ALTER", "TRUNCATE", "EXECUTE", "DECLARE":
+		return true
+	}
+	return false
+}
+
+// isOptionValue reports whether the word at i sits inside EXPLAIN's option
+// list, e.g. "EXPLAIN (FORMAT JSON) ..." or MySQL's "EXPLAIN FORMAT=JSON ...".
+func isOptionValue(rest []token, i int) bool {
+	depth := 0
+	for _, t := range rest[:i] {
+		if t.isPunct("(") {
+			depth++
+		} else if t.isPunct(")") {
+			depth--
+		}
+	}
+	return depth > 0 || (i > 0 && rest[i-1].isPunct("="))
+}
@@ -0,0 +1,264 @@
+package guard
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+const (
+	postgres = types.DialectPostgres
+	mysql    = types.DialectMySQL
+	sqlite   = types.DialectSQLite
+)
+
+var allDialects = []types.Dialect{postgres, mysql, sqlite}
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialects []types.Dialect
+		query    string
+		// rule is the expected violation, empty when the query is allowed
+		rule Rule
+	}{
+		// Plain reads
+		{"select", allDialects, "SELECT id, name FROM users WHERE age > 21", ""},
+		{"trailing semicolon", allDialects, "SELECT 1;", ""},
+		{"values", allDialects, "VALUES (1), (2)", ""},
+		{"with", allDialects, "WITH t AS (SELECT 1 AS x) SELECT x FROM t", ""},
+		{"recursive with", allDialects, "WITH RECURSIVE t(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM t WHERE n < 5) SELECT n FROM t", ""},
+		{"parenthesised union", allDialects, "(SELECT 1) UNION (SELECT 2)", ""},
+		{"table", []types.Dialect{postgres, mysql}, "TABLE users", ""},
+		{"show", []types.Dialect{postgres, mysql}, "SHOW search_path", ""},
+		{"explain", allDialects, "EXPLAIN SELECT * FROM users", ""},
+		{"explain options", []types.Dialect{postgres}, "EXPLAIN (FORMAT JSON, COSTS) SELECT 1", ""},
+		{"explain format", []types.Dialect{mysql}, "EXPLAIN FORMAT=JSON SELECT 1", ""},
+		{"describe", []types.Dialect{mysql}, "DESCRIBE users", ""},
+		{"pragma", []types.Dialect{sqlite}, "PRAGMA table_info(users)", ""},
+		{"qualified pragma", []types.Dialect{sqlite}, "PRAGMA main.index_list('users')", ""},
+		{"keyword in string", allDialects, "SELECT 'DELETE FROM users; DROP TABLE users'", ""},
+		{"keyword in line comment", allDialects, "SELECT 1 -- DELETE FROM users\n", ""},
+		{"keyword in block comment", allDialects, "SELECT /* UPDATE users SET x = 1 */ 1", ""},
+		{"keyword as quoted column", []types.Dialect{postgres, sqlite}, `SELECT "update", "into" FROM t`, ""},
+		{"keyword as backtick column", []types.Dialect{mysql, sqlite}, "SELECT `delete` FROM t", ""},
+		{"function named like dml", allDialects, "SELECT replace(name, 'a', 'b') FROM t", ""},
+		{"dollar quoted string", []types.Dialect{postgres}, "SELECT $$; DELETE FROM users$$", ""},
+		{"escape string", []types.Dialect{postgres}, `SELECT E'it\'s; DROP TABLE x'`, ""},
+		{"nested comment", []types.Dialect{postgres}, "SELECT /* a /* DELETE */ still comment */ 1", ""},
+		{"safe quoted function", []types.Dialect{postgres, sqlite}, `SELECT "lower"('A')`, ""},
+
+		// empty-query
+		{"empty", allDialects, "", RuleEmpty},
+		{"only comments", allDialects, "-- nothing here\n/* or here */", RuleEmpty},
+		{"only semicolons", allDialects, ";;", RuleEmpty},
+
+		// syntax
+		{"unterminated string", allDialects, "SELECT 'abc", RuleSyntax},
+		{"unterminated comment", allDialects, "SELECT 1 /* abc", RuleSyntax},
+		{"unterminated quoted identifier", []types.Dialect{postgres, sqlite}, `SELECT "abc`, RuleSyntax},
+		{"unterminated dollar quote", []types.Dialect{postgres}, "SELECT $x$abc", RuleSyntax},
+		{"no keyword", allDialects, "(((", RuleSyntax},
+		{"starts with literal", allDialects, "'a'", RuleSyntax},
+		{"explain without statement", []types.Dialect{postgres, sqlite}, "EXPLAIN", RuleSyntax},
+		{"pragma without name", []types.Dialect{sqlite}, "PRAGMA", RuleSyntax},
+
+		// multiple-statements
+		{"two selects", allDialects, "SELECT 1; SELECT 2", RuleMultipleStatements},
+
+		// statement-type
+		{"insert", allDialects, "INSERT INTO users (name) VALUES ('x')", RuleStatementType},
+		{"update", allDialects, "UPDATE users SET name = 'x'", RuleStatementType},
+		{"delete", allDialects, "DELETE FROM users", RuleStatementType},
+		{"drop", allDialects, "DROP TABLE users", RuleStatementType},
+		{"create", allDialects, "CREATE TABLE t (id int)", RuleStatementType},
+		{"select then delete", allDialects, "SELECT 1; DELETE FROM users", RuleStatementType},
+		{"set", allDialects, "SET search_path = evil", RuleStatementType},
+		{"call", []types.Dialect{postgres, mysql}, "CALL do_things()", RuleStatementType},
+		{"table on sqlite", []types.Dialect{sqlite}, "TABLE users", RuleStatementType},
+		{"show on sqlite", []types.Dialect{sqlite}, "SHOW tables", RuleStatementType},
+		{"describe outside mysql", []types.Dialect{postgres, sqlite}, "DESCRIBE users", RuleStatementType},
+		{"pragma outside sqlite", []types.Dialect{postgres, mysql}, "PRAGMA table_info(users)", RuleStatementType},
+		{"explain insert", allDialects, "EXPLAIN INSERT INTO users VALUES (1)", RuleStatementType},
+		{"mysql executable comment", []types.Dialect{mysql}, "/*!50000 DELETE FROM users */", RuleStatementType},
+
+		// data-modifying-cte
+		{"cte delete", []types.Dialect{postgres}, "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", RuleDataModifyingCTE},
+		{"cte insert", []types.Dialect{postgres}, "WITH i AS (INSERT INTO log VALUES (1) RETURNING id) SELECT id FROM i", RuleDataModifyingCTE},
+		{"with then update", allDialects, "WITH t AS (SELECT 1) UPDATE users SET x = 1", RuleDataModifyingCTE},
+
+		// data-modifying-clause
+		{"select with update", allDialects, "SELECT 1 UPDATE users SET x = 1", RuleDataModifyingClause},
+		{"values with delete", allDialects, "VALUES (1) DELETE FROM users", RuleDataModifyingClause},
+		{"select with merge", []types.Dialect{postgres}, "SELECT 1 MERGE INTO t USING s ON true WHEN MATCHED THEN DELETE", RuleDataModifyingClause},
+
+		// select-into
+		{"select into table", []types.Dialect{postgres}, "SELECT * INTO backup FROM users", RuleSelectInto},
+		{"select into outfile", []types.Dialect{mysql}, "SELECT * FROM users INTO OUTFILE '/tmp/x'", RuleSelectInto},
+		{"select into variable", []types.Dialect{mysql}, "SELECT id INTO @id FROM users LIMIT 1", RuleSelectInto},
+
+		// locking-clause
+		{"for update", []types.Dialect{postgres, mysql}, "SELECT * FROM users FOR UPDATE", RuleLockingClause},
+		{"for share", []types.Dialect{postgres, mysql}, "SELECT * FROM users FOR SHARE", RuleLockingClause},
+		{"for no key update", []types.Dialect{postgres}, "SELECT * FROM users FOR NO KEY UPDATE", RuleLockingClause},
+		{"for key share", []types.Dialect{postgres}, "SELECT * FROM users FOR KEY SHARE", RuleLockingClause},
+		{"lock in share mode", []types.Dialect{mysql}, "SELECT * FROM users LOCK IN SHARE MODE", RuleLockingClause},
+
+		// copy
+		{"copy to", []types.Dialect{postgres}, "COPY users TO '/tmp/users.csv'", RuleCopy},
+		{"copy from program", []types.Dialect{postgres}, "COPY users FROM PROGRAM 'rm -rf /'", RuleCopy},
+
+		// pragma-write
+		{"pragma assignment", []types.Dialect{sqlite}, "PRAGMA journal_mode = DELETE", RulePragmaWrite},
+		{"pragma not listed", []types.Dialect{sqlite}, "PRAGMA optimize", RulePragmaWrite},
+		{"pragma setter argument", []types.Dialect{sqlite}, "PRAGMA user_version(5)", RulePragmaWrite},
+		{"pragma wal checkpoint", []types.Dialect{sqlite}, "PRAGMA wal_checkpoint(TRUNCATE)", RulePragmaWrite},
+
+		// explain-analyze
+		{"explain analyze", []types.Dialect{postgres, mysql}, "EXPLAIN ANALYZE SELECT * FROM users", RuleExplainAnalyze},
+		{"explain analyse", []types.Dialect{postgres}, "EXPLAIN ANALYSE SELECT 1", RuleExplainAnalyze},
+		{"explain analyze option", []types.Dialect{postgres}, "EXPLAIN (ANALYZE, BUFFERS) SELECT 1", RuleExplainAnalyze},
+		{"explain analyze delete", []types.Dialect{postgres}, "EXPLAIN ANALYZE DELETE FROM users", RuleExplainAnalyze},
+
+		// unsafe-function
+		{"nextval", []types.Dialect{postgres}, "SELECT nextval('users_id_seq')", RuleUnsafeFunction},
+		{"set_config", []types.Dialect{postgres}, "SELECT set_config('search_path', 'evil', false)", RuleUnsafeFunction},
+		{"terminate backend", []types.Dialect{postgres}, "SELECT pg_terminate_backend(123)", RuleUnsafeFunction},
+		{"upper-case function", []types.Dialect{postgres}, "SELECT PG_SLEEP(10)", RuleUnsafeFunction},
+		{"qualified function", []types.Dialect{postgres}, "SELECT pg_catalog.pg_sleep(10)", RuleUnsafeFunction},
+		{"quoted function", []types.Dialect{postgres}, `SELECT "pg_terminate_backend"(123)`, RuleUnsafeFunction},
+		{"quoted qualified function", []types.Dialect{postgres}, `SELECT "pg_catalog"."pg_sleep"(10)`, RuleUnsafeFunction},
+		{"advisory lock prefix", []types.Dialect{postgres}, "SELECT pg_advisory_lock(1)", RuleUnsafeFunction},
+		{"function in where", []types.Dialect{postgres}, "SELECT * FROM users WHERE pg_sleep(1) IS NOT NULL", RuleUnsafeFunction},
+		{"function in cte", []types.Dialect{postgres}, "WITH s AS (SELECT pg_sleep(5)) SELECT * FROM s", RuleUnsafeFunction},
+		{"read file", []types.Dialect{postgres}, "SELECT pg_read_file('/etc/passwd')", RuleUnsafeFunction},
+		{"query_to_xml", []types.Dialect{postgres}, "SELECT query_to_xml('select pg_terminate_backend(1)', true, false, '')", RuleUnsafeFunction},
+		{"query_to_xml_and_xmlschema", []types.Dialect{postgres}, "SELECT query_to_xml_and_xmlschema('select 1', true, false, '')", RuleUnsafeFunction},
+		{"table_to_xml", []types.Dialect{postgres}, "SELECT table_to_xml('cards', true, false, '')", RuleUnsafeFunction},
+		{"schema_to_xml", []types.Dialect{postgres}, "SELECT schema_to_xml('public', true, false, '')", RuleUnsafeFunction},
+		{"database_to_xml", []types.Dialect{postgres}, "SELECT database_to_xml(true, false, '')", RuleUnsafeFunction},
+		{"cursor_to_xml", []types.Dialect{postgres}, "SELECT cursor_to_xml('c', 10, true, false, '')", RuleUnsafeFunction},
+		{"quoted query_to_xml", []types.Dialect{postgres}, `SELECT "query_to_xml"('select 1', true, false, '')`, RuleUnsafeFunction},
+		{"ts_stat", []types.Dialect{postgres}, "SELECT * FROM ts_stat('select pg_sleep(1)')", RuleUnsafeFunction},
+		{"mysql sleep", []types.Dialect{mysql}, "SELECT SLEEP(10)", RuleUnsafeFunction},
+		{"mysql backtick sleep", []types.Dialect{mysql}, "SELECT `sleep`(10)", RuleUnsafeFunction},
+		{"mysql load_file", []types.Dialect{mysql}, "SELECT LOAD_FILE('/etc/passwd')", RuleUnsafeFunction},
+		{"mysql get_lock", []types.Dialect{mysql}, "SELECT GET_LOCK('x', 10)", RuleUnsafeFunction},
+		{"mysql hint sleep", []types.Dialect{mysql}, "SELECT /*! SLEEP(10) */", RuleUnsafeFunction},
+		{"sqlite load_extension", []types.Dialect{sqlite}, "SELECT load_extension('evil.so')", RuleUnsafeFunction},
+		{"sqlite quoted load_extension", []types.Dialect{sqlite}, `SELECT "load_extension"('evil.so')`, RuleUnsafeFunction},
+		{"sqlite bracket writefile", []types.Dialect{sqlite}, "SELECT [writefile]('/tmp/x', 'data')", RuleUnsafeFunction},
+		{"explain unsafe function", []types.Dialect{postgres}, "EXPLAIN SELECT pg_sleep(1)", RuleUnsafeFunction},
+	}
+
+	for _, tt := range tests {
+		for _, dialect := range tt.dialects {
+			t.Run(string(dialect)+"/"+tt.name, func(t *testing.T) {
+				checkRule(t, CheckReadOnly(dialect, tt.query), tt.rule)
+			})
+		}
+	}
+}
+
+func TestCheckReadOnlyStatementNumber(t *testing.T) {
+	err := CheckReadOnly(postgres, "SELECT 1; SELECT 2; DROP TABLE users")
+	var v *Violation
+	if !errors.As(err, &v) {
+		t.Fatalf("CheckReadOnly() error = %v, want a *Violation", err)
+	}
+	if v.Rule != RuleStatementType || v.Statement != 3 {
+		t.Errorf("CheckReadOnly() = rule %q statement %d, want rule %q statement 3", v.Rule, v.Statement, RuleStatementType)
+	}
+}
+
+func TestCheckExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		rule Rule
+	}{
+		{"comparison", "status = 'active' AND total > 100", ""},
+		{"subquery", "id IN (SELECT user_id FROM orders)", ""},
+		{"empty", "", RuleEmpty},
+		{"semicolon", "1 = 1; DROP TABLE users", RuleMultipleStatements},
+		{"closing parenthesis", "1 = 1) OR (1 = 1", RuleSyntax},
+		{"open parenthesis", "(1 = 1", RuleSyntax},
+		{"comment out rest", "1 = 1 --", ""},
+		{"unsafe function", "pg_sleep(10) IS NULL", RuleUnsafeFunction},
+		{"quoted unsafe function", `"pg_sleep"(10) IS NULL`, RuleUnsafeFunction},
+		{"into", "1 = 1 INTO backup", RuleSelectInto},
+		{"locking", "1 = 1 FOR UPDATE", RuleLockingClause},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkRule(t, CheckExpression(postgres, tt.expr), tt.rule)
+		})
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	tests := []struct {
+		dialect types.Dialect
+		query   string
+		want    bool
+	}{
+		{postgres, "SELECT 1", true},
+		{postgres, "(SELECT 1) UNION (SELECT 2)", true},
+		{postgres, "WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{postgres, "VALUES (1)", true},
+		{postgres, "TABLE users", true},
+		{postgres, "SHOW search_path", false},
+		{postgres, "EXPLAIN SELECT 1", false},
+		{postgres, "SELECT 1; SELECT 2", false},
+		{postgres, "SELECT 'abc", false},
+		{sqlite, "PRAGMA table_info(users)", false},
+		{mysql, "DESCRIBE users", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsQuery(tt.dialect, tt.query); got != tt.want {
+			t.Errorf("IsQuery(%s, %q) = %v, want %v", tt.dialect, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"select  id,\n\tname from users -- comment\nwhere id = 1", "SELECT id, name FROM users WHERE id = 1"},
+		{"SELECT count( * ) FROM t /* x */", "SELECT count(*) FROM t"},
+		{"select 'a  b' , \"Mixed Case\" from t where x in (1,2)", `SELECT 'a  b', "Mixed Case" FROM t WHERE x IN (1, 2)`},
+		{"select id::text from t", "SELECT id::text FROM t"},
+		{"select 'unterminated", "select 'unterminated"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(postgres, tt.query); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// checkRule fails t unless err is a *Violation of rule, or nil when rule
+// is empty.
+func checkRule(t *testing.T, err error, rule Rule) {
+	t.Helper()
+	if rule == "" {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	var v *Violation
+	if !errors.As(err, &v) {
+		t.Fatalf("error = %v, want a violation of rule %q", err, rule)
+	}
+	if v.Rule != rule {
+		t.Fatalf("rule = %q (%s), want %q", v.Rule, v.Detail, rule)
+	}
+}
@@ -0,0 +1,350 @@
+package guard
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenPunct
+	tokenSemicolon
+)
+
+type token struct {
+	kind tokenKind
+	// text is the token as written in the query
+	text string
+	// value is the upper-cased word for tokenWord and the unquoted name for
+	// tokenQuotedIdent; other kinds leave it equal to text
+	value string
+	pos   int
+}
+
+func (t token) isWord(words ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, w := range words {
+		if t.value == w {
+			return true
+		}
+	}
+	return false
+}
+
+func (t token) isPunct(p string) bool {
+	return t.kind == tokenPunct && t.text == p
+}
+
+// lexer splits a query into tokens while dropping whitespace and comments.
+// It knows enough about each dialect's quoting rules that keywords hidden in
+// string literals, quoted identifiers or comments are never mistaken for code.
+type lexer struct {
+	dialect types.Dialect
+	src     string
+	pos     int
+	tokens  []token
+	// inHint is set while inside a MySQL /*! ... */ executable comment, whose
+	// contents the server runs as ordinary SQL
+	inHint bool
+}
+
+func lex(dialect types.Dialect, src string) ([]token, error) {
+	l := &lexer{dialect: dialect, src: src}
+	if err := l.run(); err != nil {
+		return nil, err
+	}
+	return l.tokens, nil
+}
+
+func (l *lexer) run() error {
+	for l.pos < len(l.src) {
+		start := l.pos
+		ch := l.src[l.pos]
+
+		switch {
+		case isSpace(ch):
+			l.pos++
+
+		case ch == '-' && l.peek(1) == '-' && l.lineCommentAllowed():
+			l.skipLine()
+
+		case ch == '#' && l.dialect == types.DialectMySQL:
+			l.skipLine()
+
+		case ch == '/' && l.peek(1) == '*':
+			if err := l.blockComment(); err != nil {
+				return err
+			}
+
+		case ch == '*' && l.peek(1) == '/' && l.inHint:
+			l.inHint = false
+			l.pos += 2
+
+		case ch == '\'':
+			if err := l.quoted('\'', tokenString, l.dialect == types.DialectMySQL); err != nil {
+				return err
+			}
+
+		case (ch == 'E' || ch == 'e') && l.peek(1) == '\'' && l.dialect == types.DialectPostgres:
+			l.pos++
+			if err := l.quoted('\'', tokenString, true); err != nil {
+				return err
+			}
+			l.tokens[len(l.tokens)-1].pos = start
+
+		case ch == '"':
+			if l.dialect == types.DialectMySQL {
+				// MySQL treats double quotes as strings unless ANSI_QUOTES is set
+				if err := l.quoted('"', tokenString, true); err != nil {
+					return err
+				}
+			} else if err := l.quoted('"', tokenQuotedIdent, false); err != nil {
+				return err
+			}
+
+		case ch == '`' && l.dialect != types.DialectPostgres:
+			if err := l.quoted('`', tokenQuotedIdent, false); err != nil {
+				return err
+			}
+
+		case ch == '[' && l.dialect == types.DialectSQLite:
+			end := strings.IndexByte(l.src[l.pos+1:], ']')
+			if end < 0 {
+				return l.errorf("unterminated bracketed identifier")
+			}
+			name := l.src[l.pos+1 : l.pos+1+end]
+			l.pos += end + 2
+			l.emit(tokenQuotedIdent, l.src[start:l.pos], name, start)
+
+		case ch == '$' && l.dialect == types.DialectPostgres:
+			if err := l.dollar(); err != nil {
+				return err
+			}
+
+		case ch == '?' || ((ch == ':' || ch == '@') && isWordStart(l.peek(1))):
+			l.pos++
+			for l.pos < len(l.src) && isWordPart(l.src[l.pos]) {
+				l.pos++
+			}
+			l.emit(tokenParam, l.src[start:l.pos], l.src[start:l.pos], start)
+
+		case ch == ';':
+			l.pos++
+			l.emit(tokenSemicolon, ";", ";", start)
+
+		case isDigit(ch) || (ch == '.' && isDigit(l.peek(1))):
+			l.number()
+
+		case isWordStart(ch):
+			for l.pos < len(l.src) && isWordPart(l.src[l.pos]) {
+				l.pos++
+			}
+			word := l.src[start:l.pos]
+			l.emit(tokenWord, word, strings.ToUpper(word), start)
+
+		default:
+			l.punct()
+		}
+	}
+
+	if l.inHint {
+		return l.errorf("unterminated comment")
+	}
+	return nil
+}
+
+func (l *lexer) emit(kind tokenKind, text, value string, pos int) {
+	l.tokens = append(l.tokens, token{kind: kind, text: text, value: value, pos: pos})
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return &Violation{
+		Rule:   RuleSyntax,
+		Detail: fmt.Sprintf(format, args...) + fmt.Sprintf(" at offset %d", l.pos),
+	}
+}
+
+// lineCommentAllowed reports whether "--" starts a comment. MySQL only
+// treats it as one when followed by whitespace or the end of input.
+func (l *lexer) lineCommentAllowed() bool {
+	if l.dialect != types.DialectMySQL {
+		return true
+	}
+	next := l.peek(2)
+	return next == 0 || isSpace(next)
+}
+
+func (l *lexer) skipLine() {
+	end := strings.IndexByte(l.src[l.pos:], '\n')
+	if end < 0 {
+		l.pos = len(l.src)
+		return
+	}
+	l.pos += end + 1
+}
+
+func (l *lexer) blockComment() error {
+	if l.dialect == types.DialectMySQL && l.peek(2) == '!' {
+		if l.inHint {
+			return l.errorf("nested executable comment")
+		}
+		// Executable comment: skip the marker and optional version number
+		// and lex the body as regular SQL.
+		l.pos += 3
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		l.inHint = true
+		return nil
+	}
+
+	// PostgreSQL block comments nest, the other dialects end at the first */
+	nests := l.dialect == types.DialectPostgres
+	depth := 0
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '/' && l.peek(1) == '*':
+			if depth == 0 || nests {
+				depth++
+			}
+			l.pos += 2
+		case l.src[l.pos] == '*' && l.peek(1) == '/':
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return nil
+			}
+		default:
+			l.pos++
+		}
+	}
+	return l.errorf("unterminated comment")
+}
+
+// quoted consumes a literal delimited by quote. A doubled quote is always an
+// escaped quote; backslashEscapes additionally honours \-escapes.
+func (l *lexer) quoted(quote byte, kind tokenKind, backslashEscapes bool) error {
+	start := l.pos
+	var value strings.Builder
+	l.pos++
+	for l.pos < len(l.src) {
+		ch := l.src[l.pos]
+		switch {
+		case backslashEscapes && ch == '\\' && l.pos+1 < len(l.src):
+			value.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+		case ch == quote && l.peek(1) == quote:
+			value.WriteByte(quote)
+			l.pos += 2
+		case ch == quote:
+			l.pos++
+			l.emit(kind, l.src[start:l.pos], value.String(), start)
+			return nil
+		default:
+			value.WriteByte(ch)
+			l.pos++
+		}
+	}
+	if kind == tokenQuotedIdent {
+		return l.errorf("unterminated quoted identifier")
+	}
+	return l.errorf("unterminated string literal")
+}
+
+// dollar handles PostgreSQL positional parameters ($1) and dollar-quoted
+// strings ($$...$$ or $tag$...$tag$).
+func (l *lexer) dollar() error {
+	start := l.pos
+	if isDigit(l.peek(1)) {
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+		l.emit(tokenParam, l.src[start:l.pos], l.src[start:l.pos], start)
+		return nil
+	}
+
+	end := l.pos + 1
+	for end < len(l.src) && l.src[end] != '$' && isWordPart(l.src[end]) {
+		end++
+	}
+	if end >= len(l.src) || l.src[end] != '$' {
+		l.punct()
+		return nil
+	}
+
+	tag := l.src[l.pos : end+1]
+	closing := strings.Index(l.src[end+1:], tag)
+	if closing < 0 {
+		return l.errorf("unterminated dollar-quoted string")
+	}
+	body := l.src[end+1 : end+1+closing]
+	l.pos = end + 1 + closing + len(tag)
+	l.emit(tokenString, l.src[start:l.pos], body, start)
+	return nil
+}
+
+func (l *lexer) number() {
+	start := l.pos
+	for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+		l.pos++
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	l.emit(tokenNumber, l.src[start:l.pos], l.src[start:l.pos], start)
+}
+
+var multiCharPuncts = []string{"::", "<=", ">=", "<>", "!=", "||", "->>", "->", ":="}
+
+func (l *lexer) punct() {
+	start := l.pos
+	for _, p := range multiCharPuncts {
+		if strings.HasPrefix(l.src[l.pos:], p) {
+			l.pos += len(p)
+			l.emit(tokenPunct, p, p, start)
+			return
+		}
+	}
+	l.pos++
+	l.emit(tokenPunct, l.src[start:l.pos], l.src[start:l.pos], start)
+}
+
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v'
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isWordStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch >= 0x80
+}
+
+func isWordPart(ch byte) bool {
+	return isWordStart(ch) || isDigit(ch) || ch == '$'
+}
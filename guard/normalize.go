@@ -0,0 +1,47 @@
+package guard
+
+import (
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// Normalize rewrites query in a canonical layout for logging: comments are
+// dropped, whitespace is collapsed to single spaces and keywords are
+// upper-cased. Identifiers and literals are kept as written. A query the
+// lexer cannot read only has its whitespace collapsed.
+func Normalize(dialect types.Dialect, query string) string {
+	tokens, err := lex(dialect, query)
+	if err != nil {
+		return strings.Join(strings.Fields(query), " ")
+	}
+
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && spaceBetween(tokens[i-1], t) {
+			b.WriteByte(' ')
+		}
+		if t.kind == tokenWord && isKeyword(t.value) {
+			b.WriteString(t.value)
+		} else {
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+// spaceBetween reports whether a space separates prev and next.
+func spaceBetween(prev, next token) bool {
+	switch {
+	case next.kind == tokenSemicolon:
+		return false
+	case next.isPunct(",") || next.isPunct(")") || next.isPunct(".") || next.isPunct("::"):
+		return false
+	case prev.isPunct("(") || prev.isPunct(".") || prev.isPunct("::"):
+		return false
+	case next.isPunct("("):
+		// Function calls keep their parenthesis; keywords such as IN do not
+		return prev.kind == tokenWord && isKeyword(prev.value) || prev.kind == tokenPunct
+	}
+	return true
+}
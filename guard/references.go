@@ -0,0 +1,489 @@
+package guard
+
+import (
+	"strings"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// TableReference is a table named in a FROM or JOIN clause.
+type TableReference struct {
+	// Name is the reference as written, possibly schema-qualified and
+	// quoted, ready to be resolved against the catalog
+	Name string
+	// Alias is the unquoted alias the statement gives the table, if any
+	Alias string
+	// ColumnAliases are the unquoted names an alias list gives the table's
+	// columns, e.g. a and b in users AS u(a, b)
+	ColumnAliases []string
+	// Scope numbers the query block (SELECT) the table belongs to
+	Scope int
+
+	// cte is the name a common table expression would need to have this
+	// reference point at it; empty for qualified names
+	cte string
+}
+
+// ColumnReference is a column named anywhere in a statement.
+type ColumnReference struct {
+	// Qualifier is the unquoted table name or alias before the column,
+	// e.g. "u" in u.email; empty for unqualified columns
+	Qualifier string
+	// Name is the unquoted column name, or "*" for a star
+	Name string
+	// Scope numbers the query block the reference appears in
+	Scope int
+}
+
+// References lists what a statement reads. It errs on the side of listing
+// too much: words that merely look like columns are included.
+type References struct {
+	Tables  []TableReference
+	Columns []ColumnReference
+	// CTEs are the common table expressions defined by WITH
+	CTEs []CTE
+	// Functions are the functions called anywhere in the statement,
+	// including table functions in FROM clauses, with quotes removed
+	Functions []string
+	// Opaque is set for statements such as SHOW, DESCRIBE or PRAGMA whose
+	// references cannot be listed
+	Opaque bool
+
+	dialect types.Dialect
+	// blocks holds the query blocks by scope number; blocks[0] is unused
+	blocks []block
+}
+
+// CTE is a common table expression.
+type CTE struct {
+	// Name is the name as the database compares it: unquoted, and folded
+	// to lower case where the dialect folds unquoted names
+	Name string
+	// Scope numbers the query block whose WITH clause defines the CTE
+	Scope int
+}
+
+// block is a query block as seen by the CTE lookup.
+type block struct {
+	parent int
+	// visible is the number of the parent's CTEs this block can see: a
+	// CTE body sees the CTEs defined before it, other blocks all of them
+	visible int
+	// recursive is set for WITH RECURSIVE, whose CTEs all see each other
+	recursive bool
+}
+
+// IsCTE reports whether t names a common table expression visible in its
+// query block rather than a table. A CTE defined inside a subquery does
+// not hide a table of the same name elsewhere in the statement.
+func (r *References) IsCTE(t TableReference) bool {
+	if t.cte == "" {
+		return false
+	}
+
+	scope, visible := t.Scope, -1
+	for scope > 0 && scope < len(r.blocks) {
+		b := r.blocks[scope]
+		seen := 0
+		for _, cte := range r.CTEs {
+			if cte.Scope != scope {
+				continue
+			}
+			if visible >= 0 && seen >= visible && !b.recursive {
+				break
+			}
+			if cte.Name == t.cte {
+				return true
+			}
+			seen++
+		}
+		scope, visible = b.parent, b.visible
+	}
+	return false
+}
+
+// newScope numbers a new query block inside parent.
+func (r *References) newScope(parent, visible int) int {
+	if len(r.blocks) == 0 {
+		r.blocks = append(r.blocks, block{})
+	}
+	r.blocks = append(r.blocks, block{parent: parent, visible: visible, recursive: r.dialect == types.DialectSQLite})
+	return len(r.blocks) - 1
+}
+
+// ctesIn counts the CTEs defined by the WITH clause of scope.
+func (r *References) ctesIn(scope int) int {
+	n := 0
+	for _, cte := range r.CTEs {
+		if cte.Scope == scope {
+			n++
+		}
+	}
+	return n
+}
+
+// cteName returns the name t would match a CTE by. PostgreSQL folds
+// unquoted names to lower case and SQLite ignores case; MySQL is compared
+// as written, so a case mismatch is treated as a table.
+func (r *References) cteName(t token) string {
+	switch {
+	case r.dialect == types.DialectSQLite:
+		return strings.ToLower(identName(t))
+	case r.dialect == types.DialectPostgres && t.kind == tokenWord:
+		return strings.ToLower(t.text)
+	default:
+		return identName(t)
+	}
+}
+
+// ExtractReferences lists the tables and columns a query refers to. It
+// does not validate the query; run CheckReadOnly first.
+func ExtractReferences(dialect types.Dialect, query string) (*References, error) {
+	tokens, err := lex(dialect, query)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := &References{dialect: dialect}
+	for _, stmt := range splitStatements(tokens) {
+		refs.statement(stmt)
+	}
+	return refs, nil
+}
+
+func (r *References) statement(stmt []token) {
+	lead := 0
+	for lead < len(stmt) && stmt[lead].isPunct("(") {
+		lead++
+	}
+	if lead == len(stmt) {
+		return
+	}
+
+	switch stmt[lead].value {
+	case "SELECT", "WITH", "VALUES", "TABLE":
+		r.walk(stmt)
+	case "EXPLAIN":
+		rest := stmt[lead+1:]
+		for i, t := range rest {
+			if t.kind == tokenWord && isStatementStart(t.value) && !isOptionValue(rest, i) {
+				r.statement(rest[i:])
+				return
+			}
+		}
+		r.Opaque = true
+	default:
+		r.Opaque = true
+	}
+}
+
+// frame tracks one level of parentheses.
+type frame struct {
+	// query is set when the parentheses hold a query, or a join inside a
+	// FROM clause, rather than an expression or argument list
+	query bool
+	// inFrom is set between FROM and the end of the FROM clause
+	inFrom bool
+	// inWith is set while reading a WITH list
+	inWith bool
+	// fromItem is set for a subquery in a FROM clause; its alias follows
+	// the closing parenthesis
+	fromItem bool
+	// cteBody is set when the next parenthesis opens the body of the CTE
+	// just named
+	cteBody bool
+	// visible limits the CTEs of scope seen by query blocks opened inside
+	// parentheses that are part of a CTE body; -1 for no limit
+	visible int
+	scope   int
+}
+
+func (r *References) walk(stmt []token) {
+	stack := []frame{{query: true, scope: r.newScope(0, 0), visible: -1}}
+
+	for i := 0; i < len(stmt); i++ {
+		t := stmt[i]
+		top := &stack[len(stack)-1]
+
+		switch {
+		case t.isPunct("("):
+			f := frame{scope: top.scope, visible: -1}
+			visible := r.ctesIn(top.scope)
+			if top.cteBody {
+				// The CTE being defined is not visible in its own body
+				visible--
+			} else if top.visible >= 0 {
+				visible = top.visible
+			}
+			next := nextWord(stmt, i)
+			if next == "SELECT" || next == "WITH" || next == "VALUES" || next == "TABLE" {
+				f.scope = r.newScope(top.scope, visible)
+				f.query = true
+				f.fromItem = top.inFrom && i > 0 && startsFromItem(stmt[i-1])
+			} else if top.inFrom && i > 0 && startsFromItem(stmt[i-1]) {
+				// A parenthesised join: (a JOIN b ON ...)
+				f.query = true
+				f.inFrom = true
+				if end := r.tableReference(stmt, i+1, f.scope); end > i+1 {
+					stack = append(stack, f)
+					i = end - 1
+					continue
+				}
+			} else if top.cteBody || top.visible >= 0 {
+				// Parentheses around a CTE body: ((SELECT ...) UNION ...)
+				f.visible = visible
+			}
+			top.cteBody = false
+			stack = append(stack, f)
+
+		case t.isPunct(")"):
+			if len(stack) == 1 {
+				continue
+			}
+			closed := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if closed.fromItem {
+				i = skipAlias(stmt, i+1) - 1
+			}
+
+		case t.isPunct(","):
+			if top.inWith {
+				i = r.cte(stmt, i+1, top.scope) - 1
+				top.cteBody = true
+			} else if top.query && top.inFrom {
+				i = r.tableReference(stmt, i+1, top.scope) - 1
+			}
+
+		case t.isPunct("*"):
+			if i > 0 && (stmt[i-1].isWord("SELECT", "DISTINCT", "ALL") || stmt[i-1].isPunct(",")) {
+				r.Columns = append(r.Columns, ColumnReference{Name: "*", Scope: top.scope})
+			}
+
+		case t.isWord("WITH") && top.query:
+			top.inWith = true
+			next := i + 1
+			if next < len(stmt) && stmt[next].isWord("RECURSIVE") {
+				r.blocks[top.scope].recursive = true
+				next++
+			}
+			i = r.cte(stmt, next, top.scope) - 1
+			top.cteBody = true
+
+		case t.isWord("SELECT") && top.query:
+			top.inWith = false
+			top.inFrom = false
+
+		case t.isWord("FROM", "JOIN") && top.query, t.isWord("STRAIGHT_JOIN") && top.query && top.inFrom:
+			top.inFrom = true
+			i = r.tableReference(stmt, i+1, top.scope) - 1
+
+		case t.isWord("TABLE") && top.query && (i == 0 || stmt[i-1].isPunct("(") || stmt[i-1].isWord("UNION", "EXCEPT", "INTERSECT", "ALL", "DISTINCT")):
+			// TABLE t is SELECT * FROM t, in a query block of its own
+			visible := r.ctesIn(top.scope)
+			if top.visible >= 0 {
+				visible = top.visible
+			}
+			scope := r.newScope(top.scope, visible)
+			if end := r.tableReference(stmt, i+1, scope); end > i+1 {
+				r.Columns = append(r.Columns, ColumnReference{Name: "*", Scope: scope})
+				i = end - 1
+			}
+
+		case t.isWord("WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "FETCH", "WINDOW", "QUALIFY", "UNION", "EXCEPT", "INTERSECT") && top.query:
+			top.inFrom = false
+
+		case isIdentifier(t):
+			i = r.column(stmt, i, top.scope) - 1
+		}
+	}
+}
+
+// startsFromItem reports whether a FROM item may follow t.
+func startsFromItem(t token) bool {
+	return t.isWord("FROM", "JOIN", "STRAIGHT_JOIN", "LATERAL") || t.isPunct(",") || t.isPunct("(")
+}
+
+// cte records the common table expression defined at i by the WITH clause
+// of scope and returns the index of the parenthesis opening its query.
+func (r *References) cte(stmt []token, i, scope int) int {
+	if i >= len(stmt) || !isIdentifier(stmt[i]) {
+		return i
+	}
+	r.CTEs = append(r.CTEs, CTE{Name: r.cteName(stmt[i]), Scope: scope})
+	i++
+
+	// Column list: name (a, b) AS (...)
+	if i < len(stmt) && stmt[i].isPunct("(") {
+		i = skipParens(stmt, i)
+	}
+	for i < len(stmt) && stmt[i].isWord("AS", "NOT", "MATERIALIZED") {
+		i++
+	}
+	return i
+}
+
+// tableReference records the FROM item starting at i and returns the index
+// after it and its alias.
+func (r *References) tableReference(stmt []token, i, scope int) int {
+	for i < len(stmt) && stmt[i].isWord("LATERAL", "ONLY") {
+		i++
+	}
+	if i >= len(stmt) || !isIdentifier(stmt[i]) {
+		return i
+	}
+
+	first := stmt[i]
+	parts := []string{first.text}
+	names := []string{identName(first)}
+	i++
+	for i+1 < len(stmt) && stmt[i].isPunct(".") && isIdentifier(stmt[i+1]) {
+		parts = append(parts, stmt[i+1].text)
+		names = append(names, identName(stmt[i+1]))
+		i += 2
+	}
+	name := strings.Join(parts, ".")
+
+	if i < len(stmt) && stmt[i].isPunct("(") {
+		r.Functions = append(r.Functions, strings.Join(names, "."))
+		return i
+	}
+
+	ref := TableReference{Name: name, Scope: scope}
+	if len(parts) == 1 {
+		ref.cte = r.cteName(first)
+	}
+	end := skipAlias(stmt, i)
+	if i < end && stmt[i].isWord("AS") {
+		i++
+	}
+	if i < end {
+		ref.Alias = identName(stmt[i])
+		i++
+	}
+	if i < end && stmt[i].isPunct("(") {
+		for j := i + 1; j < end-1; j++ {
+			if isIdentifier(stmt[j]) {
+				ref.ColumnAliases = append(ref.ColumnAliases, identName(stmt[j]))
+			}
+		}
+	}
+	r.Tables = append(r.Tables, ref)
+	return end
+}
+
+// column records the column reference starting at i, which may be
+// qualified, and returns the index after it.
+func (r *References) column(stmt []token, i, scope int) int {
+	start := i
+	parts := []string{identName(stmt[i])}
+	i++
+	for i+1 < len(stmt) && stmt[i].isPunct(".") {
+		next := stmt[i+1]
+		if next.isPunct("*") {
+			parts = append(parts, "*")
+			i += 2
+			break
+		}
+		if !isIdentifier(next) {
+			break
+		}
+		parts = append(parts, identName(next))
+		i += 2
+	}
+
+	// Functions, aliases and type names are not columns
+	if i < len(stmt) && stmt[i].isPunct("(") {
+		if len(parts) > 1 || stmt[start].kind != tokenWord || !isKeyword(stmt[start].value) {
+			r.Functions = append(r.Functions, strings.Join(parts, "."))
+		}
+		return i
+	}
+	if start > 0 && (stmt[start-1].isWord("AS") || stmt[start-1].isPunct("::")) {
+		return i
+	}
+	if len(parts) == 1 && stmt[start].kind == tokenWord && isKeyword(stmt[start].value) {
+		return i
+	}
+
+	r.Columns = append(r.Columns, ColumnReference{
+		Qualifier: strings.Join(parts[:len(parts)-1], "."),
+		Name:      parts[len(parts)-1],
+		Scope:     scope,
+	})
+	return i
+}
+
+// skipAlias returns the index after an optional [AS] alias [(columns)].
+func skipAlias(stmt []token, i int) int {
+	if i < len(stmt) && stmt[i].isWord("AS") {
+		i++
+	}
+	if i < len(stmt) && isIdentifier(stmt[i]) && !(stmt[i].kind == tokenWord && isClauseWord(stmt[i].value)) {
+		i++
+		if i < len(stmt) && stmt[i].isPunct("(") {
+			i = skipParens(stmt, i)
+		}
+	}
+	return i
+}
+
+// skipParens returns the index after the parenthesis matching the one at i.
+func skipParens(stmt []token, i int) int {
+	depth := 0
+	for ; i < len(stmt); i++ {
+		if stmt[i].isPunct("(") {
+			depth++
+		} else if stmt[i].isPunct(")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+func isIdentifier(t token) bool {
+	return t.kind == tokenQuotedIdent || t.kind == tokenWord
+}
+
+// identName returns an identifier as the database stores it when quoted,
+// or as written otherwise.
+func identName(t token) string {
+	if t.kind == tokenQuotedIdent {
+		return t.value
+	}
+	return t.text
+}
+
+// isClauseWord reports whether word ends a FROM item instead of being its
+// alias.
+func isClauseWord(word string) bool {
+	switch word {
+	case "WHERE", "JOIN", "ON", "USING", "LEFT", "RIGHT", "INNER", "OUTER", "FULL", "CROSS", "NATURAL",
+		"GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "FETCH", "WINDOW", "QUALIFY", "UNION", "EXCEPT",
+		"INTERSECT", "FOR", "LOCK", "STRAIGHT_JOIN", "USE", "IGNORE", "FORCE", "TABLESAMPLE", "LATERAL",
+		"AS", "WITH", "INDEXED", "NOT", "RETURNING", "SELECT", "VALUES", "INTO", "PARTITION":
+		return true
+	}
+	return false
+}
+
+// isKeyword reports whether an unqualified word is SQL rather than a
+// column name. Unknown words are treated as columns.
+func isKeyword(word string) bool {
+	if isClauseWord(word) || isStatementStart(word) {
+		return true
+	}
+	switch word {
+	case "FROM", "AND", "OR", "IS", "IN", "LIKE", "ILIKE", "GLOB", "REGEXP", "BETWEEN", "EXISTS", "ANY", "SOME",
+		"NULL", "TRUE", "FALSE", "UNKNOWN", "CASE", "WHEN", "THEN", "ELSE", "END", "DISTINCT", "ALL", "BY",
+		"ASC", "DESC", "NULLS", "FIRST", "LAST", "ROWS", "ROW", "ONLY", "OVER", "PRECEDING", "FOLLOWING",
+		"UNBOUNDED", "CURRENT", "RANGE", "GROUPS", "FILTER", "INTERVAL", "RECURSIVE", "MATERIALIZED",
+		"COLLATE", "ESCAPE", "SIMILAR", "TO", "AT", "ZONE", "CURRENT_DATE", "CURRENT_TIME",
+		"CURRENT_TIMESTAMP", "LOCALTIME", "LOCALTIMESTAMP", "CURRENT_USER", "SESSION_USER", "DIV", "MOD",
+		"BINARY", "XOR", "ISNULL", "NOTNULL", "DATE", "TIME", "TIMESTAMP":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package guard
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/types"
+)
+
+func TestExtractReferencesCTEs(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialects []types.Dialect
+		query    string
+		// tables are the references that name tables rather than CTEs
+		tables []string
+	}{
+		{"cte", allDialects, "WITH cards AS (SELECT 1) SELECT * FROM cards", nil},
+		{"cte in subquery of main query", allDialects, "WITH x AS (SELECT 1) SELECT * FROM (SELECT * FROM x) y", nil},
+		{"earlier cte", allDialects, "WITH a AS (SELECT 1), b AS (SELECT * FROM a) SELECT * FROM b", nil},
+		{"cte in sibling subquery", allDialects, "SELECT c.* FROM cards c, (WITH cards AS (SELECT 1) SELECT 1) s", []string{"cards"}},
+		{"cte in later subquery", allDialects, "SELECT * FROM (WITH cards AS (SELECT 1) SELECT 1) s, cards", []string{"cards"}},
+		{"cte in where subquery", allDialects, "SELECT * FROM cards WHERE EXISTS (WITH cards AS (SELECT 1) SELECT * FROM cards)", []string{"cards"}},
+		{"qualified name", allDialects, "WITH cards AS (SELECT 1) SELECT * FROM main.cards", []string{"main.cards"}},
+		{"self reference", []types.Dialect{postgres, mysql}, "WITH cards AS (SELECT * FROM cards) SELECT * FROM cards", []string{"cards"}},
+		{"self reference in parentheses", []types.Dialect{postgres, mysql}, "WITH cards AS ((SELECT 1) UNION (SELECT * FROM cards)) SELECT * FROM cards", []string{"cards"}},
+		{"later cte", []types.Dialect{postgres, mysql}, "WITH a AS (SELECT * FROM b), b AS (SELECT 1) SELECT * FROM a", []string{"b"}},
+		{"recursive self reference", allDialects, "WITH RECURSIVE t AS (SELECT 1 UNION ALL SELECT 1 FROM t) SELECT * FROM t", nil},
+		{"sqlite self reference", []types.Dialect{sqlite}, "WITH t AS (SELECT 1 UNION ALL SELECT 1 FROM t) SELECT * FROM t", nil},
+		{"quoted cte", []types.Dialect{postgres}, `WITH "Cards" AS (SELECT 1) SELECT * FROM cards, "Cards"`, []string{"cards"}},
+		{"folded cte", []types.Dialect{postgres}, `WITH Cards AS (SELECT 1) SELECT * FROM "cards"`, nil},
+		{"case-insensitive cte", []types.Dialect{sqlite}, "WITH Cards AS (SELECT 1) SELECT * FROM cards", nil},
+	}
+
+	for _, tt := range tests {
+		for _, dialect := range tt.dialects {
+			t.Run(string(dialect)+"/"+tt.name, func(t *testing.T) {
+				refs, err := ExtractReferences(dialect, tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var tables []string
+				for _, ref := range refs.Tables {
+					if !refs.IsCTE(ref) {
+						tables = append(tables, ref.Name)
+					}
+				}
+				if !reflect.DeepEqual(tables, tt.tables) {
+					t.Errorf("tables = %q, want %q", tables, tt.tables)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractReferencesFunctions(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT query_to_xml('select 1', true, false, '')", []string{"query_to_xml"}},
+		{`SELECT pg_catalog."table_to_xml"('cards', true, false, '')`, []string{"pg_catalog.table_to_xml"}},
+		{"SELECT * FROM generate_series(1, 3)", []string{"generate_series"}},
+		{"SELECT count(*) FROM t WHERE id IN (SELECT 1) AND EXISTS (SELECT 1)", []string{"count"}},
+	}
+
+	for _, tt := range tests {
+		refs, err := ExtractReferences(postgres, tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(refs.Functions, tt.want) {
+			t.Errorf("ExtractReferences(%q).Functions = %q, want %q", tt.query, refs.Functions, tt.want)
+		}
+	}
+}
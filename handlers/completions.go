@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/databases/catalog"
+)
+
+// completionMaxValues is the most values a completion result may carry
+const completionMaxValues = 100
+
+// Completer answers completion/complete requests from the catalog. It
+// completes these arguments of any prompt, resource template or tool:
+//
+//	database         configured database names
+//	table, name      table names, schema-qualified
+//	tables           the last entry of a comma-separated table list
+//	columns,order_by the last entry of a column list, for the table given
+//	                 in the request's context arguments
+//
+// Names come from the connectors' schema cache, so typing does not query
+// the database on every keystroke unless schema_cache_ttl disables it.
+type Completer struct {
+	manager *databases.Manager
+}
+
+// NewCompleter creates a completer for the databases in manager.
+func NewCompleter(manager *databases.Manager) *Completer {
+	return &Completer{manager: manager}
+}
+
+// Complete returns the values for the argument named in request. Arguments
+// the client has already filled in, such as database or table, are passed in
+// filled.
+func (c *Completer) Complete(ctx context.Context, request mcp.CompleteRequest, filled map[string]string) (*mcp.CompleteResult, error) {
+	argument := request.Params.Argument.Name
+	value := request.Params.Argument.Value
+
+	var candidates []string
+	var err error
+	switch argument {
+	case "database":
+		candidates = c.manager.Names()
+	case "table", "name", "tables":
+		if argument == "name" && refType(request.Params.Ref) != "ref/resource" {
+			break
+		}
+		candidates, err = c.tableNames(ctx, filled["database"])
+	case "columns", "order_by":
+		if filled["table"] == "" {
+			break
+		}
+		candidates, err = c.columnNames(ctx, filled["database"], filled["table"])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// List arguments complete their last entry and keep the ones before it
+	prefix := ""
+	if argument == "tables" || argument == "columns" || argument == "order_by" {
+		if i := strings.LastIndex(value, ","); i >= 0 {
+			prefix, value = value[:i+1]+" ", strings.TrimSpace(value[i+1:])
+		}
+	}
+
+	matches := catalog.Complete(candidates, value)
+
+	result := &mcp.CompleteResult{}
+	result.Completion.Total = len(matches)
+	if len(matches) > completionMaxValues {
+		matches = matches[:completionMaxValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = make([]string, len(matches))
+	for i, m := range matches {
+		result.Completion.Values[i] = prefix + m
+	}
+	return result, nil
+}
+
+// tableNames returns the names of every visible table, qualified unless the
+// table is in the default schema.
+func (c *Completer) tableNames(ctx context.Context, database string) ([]string, error) {
+	conn, err := c.manager.Get(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := conn.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := conn.ListSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defaultSchema := ""
+	for _, schema := range schemas {
+		if schema.Default {
+			defaultSchema = schema.Name
+		}
+	}
+
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Schema == defaultSchema {
+			ref.Schema = ""
+		}
+		names = append(names, ref.Ident(conn.Dialect()))
+	}
+	return names, nil
+}
+
+// columnNames returns the column names of a table.
+func (c *Completer) columnNames(ctx context.Context, database, table string) ([]string, error) {
+	conn, err := c.manager.Get(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	scan, err := conn.Scan(ctx, []string{table})
+	if err != nil {
+		return nil, err
+	}
+	if len(scan.Tables) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	names := make([]string, 0, len(scan.Tables[0].Columns))
+	for _, col := range scan.Tables[0].Columns {
+		names = append(names, col.Name)
+	}
+	return names, nil
+}
+
+// refType returns the type of a completion reference, e.g. ref/prompt.
+func refType(ref any) string {
+	if m, ok := ref.(map[string]any); ok {
+		t, _ := m["type"].(string)
+		return t
+	}
+	return ""
+}
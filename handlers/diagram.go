@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/diagram"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// diagramDefaultHops is how far around a focus table schema_diagram
+// reaches when no hops are given.
+const diagramDefaultHops = 1
+
+// DiagramURI is the resource holding the Mermaid diagram of a database.
+func DiagramURI(database string) string {
+	return fmt.Sprintf("db://%s/diagram", url.PathEscape(database))
+}
+
+// SchemaDiagramHandler creates a handler for the schema_diagram tool
+func SchemaDiagramHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		hops := request.GetInt("hops", diagramDefaultHops)
+		if hops < 0 {
+			hops = diagramDefaultHops
+		}
+
+		graph, err := connector.SchemaGraph(ctx, types.DiagramOptions{
+			Tables:          stringListArgument(request, "tables"),
+			Focus:           request.GetString("focus", ""),
+			Hops:            hops,
+			IncludeInferred: request.GetBool("include_inferred", true),
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Diagram failed: %v", err)), nil
+		}
+
+		text, err := diagram.Render(diagram.Format(request.GetString("format", string(diagram.FormatMermaid))), graph)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Diagram failed: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+// DiagramResourceHandler creates a handler for the db://{database}/diagram
+// resource, a Mermaid diagram of every table.
+func DiagramResourceHandler(manager *databases.Manager, database string) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return diagramResource(ctx, manager, database, request.Params.URI, types.DiagramOptions{IncludeInferred: true})
+	}
+}
+
+// TableDiagramResourceHandler creates a handler for the
+// db://{database}/diagram/{table} resource template, a Mermaid diagram of
+// the table and the tables it is directly related to.
+func TableDiagramResourceHandler(manager *databases.Manager) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		database, err := templateArgument(request, "database")
+		if err != nil {
+			return nil, err
+		}
+		table, err := templateArgument(request, "table")
+		if err != nil {
+			return nil, err
+		}
+
+		return diagramResource(ctx, manager, database, request.Params.URI, types.DiagramOptions{
+			Focus:           table,
+			Hops:            diagramDefaultHops,
+			IncludeInferred: true,
+		})
+	}
+}
+
+func diagramResource(ctx context.Context, manager *databases.Manager, database, uri string, opts types.DiagramOptions) ([]mcp.ResourceContents, error) {
+	conn, err := manager.Get(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := conn.SchemaGraph(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("diagram failed: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "text/plain",
+			Text:     diagram.Mermaid(graph),
+		},
+	}, nil
+}
@@ -3,26 +3,69 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/audit"
+	"github.com/melkeydev/mcp-database/config"
 	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/databases/catalog"
+	"github.com/melkeydev/mcp-database/guard"
+	"github.com/melkeydev/mcp-database/history"
+	"github.com/melkeydev/mcp-database/redact"
+	"github.com/melkeydev/mcp-database/types"
 )
 
 // SampleHandler creates a handler for the sample_table tool
-func SampleHandler(connector databases.DatabaseConnector) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func SampleHandler(manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
 		table, err := request.RequireString("table")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Missing table parameter: %v", err)), nil
 		}
 
-		limit := 10
+		limit := request.GetInt("limit", limits.SampleDefaultRows)
+		if limit <= 0 {
+			limit = limits.SampleDefaultRows
+		}
+		if limit > limits.SampleMaxRows {
+			limit = limits.SampleMaxRows
+		}
 
-		results, err := connector.Sample(ctx, table, limit)
+		opts := types.SampleOptions{
+			Limit:   limit,
+			Columns: stringListArgument(request, "columns"),
+			OrderBy: request.GetString("order_by", ""),
+			Where:   request.GetString("where", ""),
+			Random:  request.GetBool("random", false),
+			// Samples are bounded like query_database results
+			MaxBytes: limits.MaxResultBytes,
+			Timeout:  limits.QueryTimeout,
+			OnQuery: func(query string) {
+				audit.SetSQL(ctx, connector.Dialect(), query)
+			},
+		}
+
+		ref, results, err := connector.Sample(ctx, table, opts)
 		if err != nil {
+			if rejected(err) {
+				audit.SetBlocked(ctx)
+			}
+			if errors.Is(err, context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("Sample timed out after %v", limits.QueryTimeout)), nil
+			}
 			return mcp.NewToolResultError(fmt.Sprintf("Sample failed: %v", err)), nil
 		}
+		audit.SetRowCount(ctx, len(results))
+		redactTable(policy, ref, results)
 
 		jsonData, err := json.MarshalIndent(results, "", "  ")
 		if err != nil {
@@ -34,16 +77,75 @@ func SampleHandler(connector databases.DatabaseConnector) func(context.Context,
 }
 
 // QueryHandler creates a handler for the query_database tool
-func QueryHandler(connector databases.DatabaseConnector) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func QueryHandler(manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy, hist *history.History) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
 		query, err := request.RequireString("query")
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Missing query parameter: %v", err)), nil
 		}
 
-		results, err := connector.Query(ctx, query)
+		audit.SetSQL(ctx, connector.Dialect(), query)
+
+		if err := guard.CheckReadOnly(connector.Dialect(), query); err != nil {
+			audit.SetBlocked(ctx)
+			return mcp.NewToolResultError(fmt.Sprintf("Query rejected: %v", err)), nil
+		}
+		if err := connector.CheckAccess(ctx, query); err != nil {
+			if rejected(err) {
+				audit.SetBlocked(ctx)
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Query rejected: %v", err)), nil
+		}
+
+		maxRows := request.GetInt("max_rows", limits.MaxRows)
+		if maxRows <= 0 || maxRows > limits.MaxRows {
+			maxRows = limits.MaxRows
+		}
+
+		timeout := time.Duration(request.GetInt("timeout_ms", 0)) * time.Millisecond
+		if timeout <= 0 || timeout > limits.QueryTimeout {
+			timeout = limits.QueryTimeout
+		}
+
+		if (limits.MaxPlanCost > 0 || limits.MaxPlanRows > 0) && guard.IsQuery(connector.Dialect(), query) {
+			plan, err := connector.Explain(ctx, query, timeout)
+			if err != nil {
+				return mcp.NewToolResultError(queryError(err, timeout)), nil
+			}
+			if err := checkPlanLimits(plan, limits); err != nil {
+				audit.SetBlocked(ctx)
+				return mcp.NewToolResultError(fmt.Sprintf("Query rejected: %v", err)), nil
+			}
+		}
+
+		start := time.Now()
+		results, err := connector.Query(ctx, query, types.QueryOptions{
+			MaxRows:  maxRows,
+			MaxBytes: limits.MaxResultBytes,
+			Timeout:  timeout,
+		})
+		entry := history.Entry{
+			Time:       start.UTC(),
+			Database:   connector.Name,
+			Query:      query,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		}
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Query failed: %v", err)), nil
+			entry.Error = queryError(err, timeout)
+			hist.Add(ctx, entry)
+			return mcp.NewToolResultError(entry.Error), nil
+		}
+		entry.RowCount = &results.RowCount
+		entry.Truncated = results.Truncated
+		hist.Add(ctx, entry)
+		audit.SetRowCount(ctx, results.RowCount)
+		if policy != nil {
+			policy.QueryRows(querySources(ctx, connector, query), results.Rows)
 		}
 
 		jsonData, err := json.MarshalIndent(results, "", "  ")
@@ -55,23 +157,94 @@ func QueryHandler(connector databases.DatabaseConnector) func(context.Context, m
 	}
 }
 
-// ScanHandler creates a handler for the scan_database tool
-func ScanHandler(connector databases.DatabaseConnector) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// ExplainResult is the response of the explain_query tool.
+type ExplainResult struct {
+	*types.QueryPlan
+	// LimitExceeded explains why query_database would refuse the query
+	LimitExceeded string `json:"limit_exceeded,omitempty"`
+}
+
+// ExplainHandler creates a handler for the explain_query tool
+func ExplainHandler(manager *databases.Manager, limits config.LimitsConfig) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		var tablesList []string
-
-		if args, ok := request.Params.Arguments.(map[string]any); ok {
-			if tablesParam, exists := args["tables"]; exists {
-				if tablesArray, ok := tablesParam.([]interface{}); ok {
-					for _, table := range tablesArray {
-						if tableStr, ok := table.(string); ok {
-							tablesList = append(tablesList, tableStr)
-						}
-					}
-				}
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		query, err := request.RequireString("query")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Missing query parameter: %v", err)), nil
+		}
+		audit.SetSQL(ctx, connector.Dialect(), query)
+
+		if err := guard.CheckReadOnly(connector.Dialect(), query); err != nil {
+			audit.SetBlocked(ctx)
+			return mcp.NewToolResultError(fmt.Sprintf("Query rejected: %v", err)), nil
+		}
+		if !guard.IsQuery(connector.Dialect(), query) {
+			return mcp.NewToolResultError("Query rejected: only SELECT queries can be explained"), nil
+		}
+		if err := connector.CheckAccess(ctx, query); err != nil {
+			if rejected(err) {
+				audit.SetBlocked(ctx)
 			}
+			return mcp.NewToolResultError(fmt.Sprintf("Query rejected: %v", err)), nil
+		}
+
+		plan, err := connector.Explain(ctx, query, limits.QueryTimeout)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Explain failed: %v", err)), nil
+		}
+
+		result := ExplainResult{QueryPlan: plan}
+		if err := checkPlanLimits(plan, limits); err != nil {
+			result.LimitExceeded = err.Error()
+		}
+
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// checkPlanLimits returns an error when a plan's estimates exceed the
+// configured limits. Estimates the planner does not give pass.
+func checkPlanLimits(plan *types.QueryPlan, limits config.LimitsConfig) error {
+	if limits.MaxPlanCost > 0 && plan.TotalCost != nil && *plan.TotalCost > limits.MaxPlanCost {
+		return fmt.Errorf("estimated cost %.0f exceeds the limit of %.0f, narrow the query or inspect it with explain_query", *plan.TotalCost, limits.MaxPlanCost)
+	}
+	if limits.MaxPlanRows > 0 && plan.EstimatedRows != nil && *plan.EstimatedRows > limits.MaxPlanRows {
+		return fmt.Errorf("estimated %.0f rows exceed the limit of %.0f, narrow the query or inspect it with explain_query", *plan.EstimatedRows, limits.MaxPlanRows)
+	}
+	return nil
+}
+
+// queryError is the message returned for a query that failed to run.
+func queryError(err error, timeout time.Duration) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("Query timed out after %v", timeout)
+	case errors.Is(err, context.Canceled):
+		return "Query cancelled"
+	default:
+		return fmt.Sprintf("Query failed: %v", err)
+	}
+}
+
+// ScanHandler creates a handler for the scan_database tool
+func ScanHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
 		}
 
+		tablesList := stringListArgument(request, "tables")
+
 		tables, err := connector.Scan(ctx, tablesList)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Scan failed: %v", err)), nil
@@ -85,3 +258,322 @@ func ScanHandler(connector databases.DatabaseConnector) func(context.Context, mc
 		return mcp.NewToolResultText(string(jsonData)), nil
 	}
 }
+
+// DescribeHandler creates a handler for the describe_table tool
+func DescribeHandler(manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		table, err := request.RequireString("table")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Missing table parameter: %v", err)), nil
+		}
+
+		opts := types.DescribeOptions{
+			SkipRowCount:   request.GetBool("skip_row_count", false),
+			SkipSampleData: request.GetBool("skip_sample_data", false),
+			SampleMaxBytes: limits.MaxResultBytes,
+			SampleTimeout:  limits.QueryTimeout,
+			CountTimeout:   limits.QueryTimeout,
+		}
+
+		ref, description, err := connector.DescribeTable(ctx, table, opts)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("Row count timed out after %v, pass skip_row_count=true to leave it out", limits.QueryTimeout)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Describe failed: %v", err)), nil
+		}
+		redactTable(policy, ref, description.SampleData)
+
+		jsonData, err := json.MarshalIndent(description, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// stringListArgument reads an argument that may be given either as a JSON
+// array of strings or as a single comma-separated string.
+func stringListArgument(request mcp.CallToolRequest, key string) []string {
+	var values []string
+	switch raw := request.GetArguments()[key].(type) {
+	case string:
+		values = strings.Split(raw, ",")
+	case []any:
+		for _, item := range raw {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// ListSchemasHandler creates a handler for the list_schemas tool
+func ListSchemasHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		schemas, err := connector.ListSchemas(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("List schemas failed: %v", err)), nil
+		}
+
+		jsonData, err := json.MarshalIndent(schemas, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// ListObjectsHandler creates a handler for the list_objects tool
+func ListObjectsHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		objects, err := connector.ListObjects(ctx, stringListArgument(request, "kinds"))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("List objects failed: %v", err)), nil
+		}
+
+		jsonData, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// DescribeObjectHandler creates a handler for the describe_object tool
+func DescribeObjectHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Missing name parameter: %v", err)), nil
+		}
+
+		objects, err := connector.DescribeObject(ctx, name, request.GetString("kind", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Describe object failed: %v", err)), nil
+		}
+
+		jsonData, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// ListRelationshipsHandler creates a handler for the list_relationships tool
+func ListRelationshipsHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		relationships, err := connector.Relationships(ctx, stringListArgument(request, "tables"))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("List relationships failed: %v", err)), nil
+		}
+
+		if !request.GetBool("include_inferred", true) {
+			declared := []types.Relationship{}
+			for _, r := range relationships {
+				if !r.Inferred {
+					declared = append(declared, r)
+				}
+			}
+			relationships = declared
+		}
+
+		jsonData, err := json.MarshalIndent(relationships, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// FindJoinPathHandler creates a handler for the find_join_path tool
+func FindJoinPathHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		tables := stringListArgument(request, "tables")
+		if len(tables) < 2 {
+			return mcp.NewToolResultError("Missing tables parameter: name at least two tables to join"), nil
+		}
+
+		opts := types.JoinPathOptions{
+			IncludeInferred: request.GetBool("include_inferred", false),
+			MaxPaths:        request.GetInt("max_paths", 3),
+		}
+
+		paths, err := connector.JoinPaths(ctx, tables, opts)
+		if err != nil {
+			message := fmt.Sprintf("Find join path failed: %v", err)
+			if !opts.IncludeInferred {
+				message += ". Set include_inferred=true to also follow relationships inferred from column names"
+			}
+			return mcp.NewToolResultError(message), nil
+		}
+
+		jsonData, err := json.MarshalIndent(paths, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// ListDatabasesHandler creates a handler for the list_databases tool
+func ListDatabasesHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		statuses := manager.Status(ctx)
+
+		jsonData, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// connection resolves the optional database argument to an open connection.
+// The second return value is the tool error to send back when it fails.
+func connection(ctx context.Context, manager *databases.Manager, request mcp.CallToolRequest) (*databases.Connection, *mcp.CallToolResult) {
+	conn, err := manager.Get(ctx, request.GetString("database", ""))
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Database unavailable: %v", err))
+	}
+	audit.SetDatabase(ctx, conn.Name)
+	return conn, nil
+}
+
+// rejected reports whether err comes from the read-only guard or the
+// access rules rather than the database.
+func rejected(err error) bool {
+	var violation *guard.Violation
+	var denied *catalog.AccessViolation
+	return errors.As(err, &violation) || errors.As(err, &denied)
+}
+
+// redactTable applies policy to rows read from ref. A view, like anything
+// else that is not a base table, could read any table, so its rows are
+// redacted as coming from an unknown source.
+func redactTable(policy *redact.Policy, ref catalog.TableRef, rows []map[string]any) {
+	if ref.Type != catalog.TypeBaseTable {
+		policy.QueryRows([]redact.Source{{}}, rows)
+		return
+	}
+	policy.Rows(ref.Schema, ref.Name, rows)
+}
+
+// querySources lists the tables query reads for redaction. Anything that
+// is not a visible base table, such as a view, could read any table and is
+// returned as an unknown source, as is the whole statement when its tables
+// cannot be listed.
+func querySources(ctx context.Context, connector *databases.Connection, query string) []redact.Source {
+	unknown := []redact.Source{{}}
+
+	refs, err := guard.ExtractReferences(connector.Dialect(), query)
+	if err != nil || refs.Opaque {
+		return unknown
+	}
+	tables, err := connector.ListTables(ctx)
+	if err != nil {
+		return unknown
+	}
+
+	sources := []redact.Source{}
+	for _, ref := range refs.Tables {
+		if refs.IsCTE(ref) {
+			continue
+		}
+		schema, name, _, err := catalog.ParseName(connector.Dialect(), ref.Name)
+		if err != nil {
+			return unknown
+		}
+
+		// An unqualified name may be any of the tables of that name
+		found := false
+		for _, t := range tables {
+			if !strings.EqualFold(t.Name, name) || (schema != "" && !strings.EqualFold(t.Schema, schema)) {
+				continue
+			}
+			if t.Type != catalog.TypeBaseTable {
+				return unknown
+			}
+			sources = append(sources, redact.Source{Schema: t.Schema, Table: t.Name})
+			found = true
+		}
+		if !found {
+			return unknown
+		}
+	}
+	return sources
+}
+
+// RefreshSchemaHandler creates a handler for the refresh_schema tool
+func RefreshSchemaHandler(manager *databases.Manager) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		connector, errResult := connection(ctx, manager, request)
+		if errResult != nil {
+			return errResult, nil
+		}
+
+		tables := stringListArgument(request, "tables")
+		refreshed, err := connector.RefreshSchema(ctx, tables)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Refresh failed: %v", err)), nil
+		}
+
+		result := types.RefreshResult{Database: connector.Name, All: len(tables) == 0}
+		for _, ref := range refreshed {
+			result.Tables = append(result.Tables, ref.String())
+		}
+
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
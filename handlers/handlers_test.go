@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+func TestCheckPlanLimits(t *testing.T) {
+	estimate := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name    string
+		plan    types.QueryPlan
+		limits  config.LimitsConfig
+		allowed bool
+	}{
+		{"no limits", types.QueryPlan{TotalCost: estimate(1e9), EstimatedRows: estimate(1e9)}, config.LimitsConfig{}, true},
+		{"under both", types.QueryPlan{TotalCost: estimate(100), EstimatedRows: estimate(10)}, config.LimitsConfig{MaxPlanCost: 1000, MaxPlanRows: 100}, true},
+		{"at the limits", types.QueryPlan{TotalCost: estimate(1000), EstimatedRows: estimate(100)}, config.LimitsConfig{MaxPlanCost: 1000, MaxPlanRows: 100}, true},
+		{"cost over", types.QueryPlan{TotalCost: estimate(1000.5), EstimatedRows: estimate(10)}, config.LimitsConfig{MaxPlanCost: 1000, MaxPlanRows: 100}, false},
+		{"rows over", types.QueryPlan{TotalCost: estimate(10), EstimatedRows: estimate(101)}, config.LimitsConfig{MaxPlanCost: 1000, MaxPlanRows: 100}, false},
+		{"rows over without a cost limit", types.QueryPlan{TotalCost: estimate(1e9), EstimatedRows: estimate(101)}, config.LimitsConfig{MaxPlanRows: 100}, false},
+		{"no estimates", types.QueryPlan{Dialect: types.DialectSQLite}, config.LimitsConfig{MaxPlanCost: 1, MaxPlanRows: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPlanLimits(&tt.plan, tt.limits)
+			if (err == nil) != tt.allowed {
+				t.Errorf("checkPlanLimits() error = %v, want allowed = %v", err, tt.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/history"
+)
+
+// HistoryURI is the resource holding the session's query history.
+const HistoryURI = "db://history"
+
+// historyDefaultLimit is the number of entries query_history returns when
+// no limit is given.
+const historyDefaultLimit = 20
+
+// HistoryListing is the content of the query_history tool and the
+// db://history resource.
+type HistoryListing struct {
+	// Entries are the matching queries, newest first
+	Entries []history.Entry `json:"entries"`
+	// Total is the number of queries held for the session
+	Total int `json:"total"`
+}
+
+// QueryHistoryHandler creates a handler for the query_history tool
+func QueryHistoryHandler(hist *history.History) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limit := request.GetInt("limit", historyDefaultLimit)
+		if limit <= 0 {
+			limit = historyDefaultLimit
+		}
+
+		entries, total := hist.List(ctx, history.Filter{
+			Database: request.GetString("database", ""),
+			Search:   request.GetString("search", ""),
+			Failed:   request.GetBool("errors_only", false),
+			Limit:    limit,
+		})
+
+		jsonData, err := json.MarshalIndent(HistoryListing{Entries: nonNil(entries), Total: total}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal results: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonData)), nil
+	}
+}
+
+// HistoryResourceHandler creates a handler for the db://history resource
+func HistoryResourceHandler(hist *history.History) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		entries, total := hist.List(ctx, history.Filter{})
+		return jsonResource(request.Params.URI, HistoryListing{Entries: nonNil(entries), Total: total})
+	}
+}
+
+// nonNil keeps an empty history encoded as [] rather than null.
+func nonNil(entries []history.Entry) []history.Entry {
+	if entries == nil {
+		return []history.Entry{}
+	}
+	return entries
+}
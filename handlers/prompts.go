@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/redact"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+const (
+	// summaryMaxTables caps the tables listed in the explore_database summary
+	summaryMaxTables = 100
+	// summaryMaxColumns caps the columns listed per table in the summary
+	summaryMaxColumns = 20
+	// metricMaxTables caps the table descriptions investigate_metric attaches
+	metricMaxTables = 5
+)
+
+// ExplorePromptHandler creates a handler for the explore_database prompt. It
+// combines the tool usage guide with a compact summary of the schema.
+func ExplorePromptHandler(manager *databases.Manager, guide string) func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		conn, err := manager.Get(ctx, request.Params.Arguments["database"])
+		if err != nil {
+			return nil, err
+		}
+
+		scan, err := conn.Scan(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		var b strings.Builder
+		b.WriteString(strings.TrimSpace(guide))
+		fmt.Fprintf(&b, "\n\nYou are exploring the %s database (%s). Pass database=%q to the tools.\n", conn.Name, conn.Dialect(), conn.Name)
+		fmt.Fprintf(&b, "\nSchema summary (%d tables and views, columns as name type, ? marks nullable):\n", len(scan.Tables))
+		writeSchemaSummary(&b, scan.Tables)
+		b.WriteString("\nStart by asking what the user wants to find out, then use describe_table and sample_table on the relevant tables before writing queries.")
+
+		return mcp.NewGetPromptResult(
+			fmt.Sprintf("Explore the %s database", conn.Name),
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(b.String())),
+			},
+		), nil
+	}
+}
+
+// AnalyzeTablePromptHandler creates a handler for the analyze_table prompt.
+// The table's description, including sample rows, is attached as a resource.
+func AnalyzeTablePromptHandler(manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy) func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		table := strings.TrimSpace(request.Params.Arguments["table"])
+		if table == "" {
+			return nil, fmt.Errorf("missing table argument")
+		}
+
+		conn, err := manager.Get(ctx, request.Params.Arguments["database"])
+		if err != nil {
+			return nil, err
+		}
+
+		ref, description, err := conn.DescribeTable(ctx, table, types.DescribeOptions{
+			SampleMaxBytes: limits.MaxResultBytes,
+			SampleTimeout:  limits.QueryTimeout,
+			CountTimeout:   limits.QueryTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describe failed: %w", err)
+		}
+		redactTable(policy, ref, description.SampleData)
+
+		attached, err := tableDescriptionMessage(conn.Name, ref.Ident(conn.Dialect()), description)
+		if err != nil {
+			return nil, err
+		}
+
+		text := fmt.Sprintf(`Analyze the table %s in the %s database (%s). Its description is attached.
+
+1. Explain what one row represents and what the key columns mean.
+2. Check data quality with query_database: NULL rates, duplicate keys, out-of-range or inconsistent values.
+3. Describe the distribution of the most important columns and how the data changes over time if there is a timestamp.
+4. Point out columns that look like foreign keys and the tables they probably reference.
+
+Use database=%q with the tools and keep queries aggregated rather than pulling many raw rows.`, table, conn.Name, conn.Dialect(), conn.Name)
+
+		return mcp.NewGetPromptResult(
+			fmt.Sprintf("Analyze %s", table),
+			[]mcp.PromptMessage{
+				mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+				attached,
+			},
+		), nil
+	}
+}
+
+// InvestigateMetricPromptHandler creates a handler for the investigate_metric
+// prompt. Tables named in the tables argument are attached; otherwise the
+// tables whose names and columns best match the question are picked.
+func InvestigateMetricPromptHandler(manager *databases.Manager, limits config.LimitsConfig) func(context.Context, mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		question := strings.TrimSpace(request.Params.Arguments["question"])
+		if question == "" {
+			return nil, fmt.Errorf("missing question argument")
+		}
+
+		conn, err := manager.Get(ctx, request.Params.Arguments["database"])
+		if err != nil {
+			return nil, err
+		}
+
+		var tables []string
+		for _, t := range strings.Split(request.Params.Arguments["tables"], ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tables = append(tables, t)
+			}
+		}
+		if len(tables) == 0 {
+			scan, err := conn.Scan(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("scan failed: %w", err)
+			}
+			tables = relevantTables(question, scan.Tables, metricMaxTables)
+		}
+
+		var messages []mcp.PromptMessage
+		var attachedNames []string
+		for _, table := range tables {
+			ref, description, err := conn.DescribeTable(ctx, table, types.DescribeOptions{
+				SkipSampleData: true,
+				CountTimeout:   limits.QueryTimeout,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("describe %s failed: %w", table, err)
+			}
+			name := ref.Ident(conn.Dialect())
+			message, err := tableDescriptionMessage(conn.Name, name, description)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, message)
+			attachedNames = append(attachedNames, name)
+		}
+
+		tablesNote := "No table matched the question, so start with scan_database to find the relevant tables."
+		if len(attachedNames) > 0 {
+			tablesNote = fmt.Sprintf("The descriptions of the tables that look relevant are attached: %s. Use scan_database if others are needed.", strings.Join(attachedNames, ", "))
+		}
+
+		text := fmt.Sprintf(`Investigate this question using the %s database (%s):
+
+%s
+
+%s
+
+1. State precisely how the metric is defined: the tables, filters, grain and time window.
+2. Check your assumptions about the data with sample_table or small queries before relying on them.
+3. Answer with query_database, showing the final SQL and the result.
+4. Call out caveats such as missing data, duplicates or ambiguous definitions.
+
+Use database=%q with the tools.`, conn.Name, conn.Dialect(), question, tablesNote, conn.Name)
+
+		messages = append([]mcp.PromptMessage{mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text))}, messages...)
+
+		return mcp.NewGetPromptResult("Investigate a metric", messages), nil
+	}
+}
+
+// tableDescriptionMessage attaches a table description as an embedded
+// resource with the same URI as the table resource. name is the resolved
+// table's Ident, as registered by ListTableItems.
+func tableDescriptionMessage(database, name string, description *types.TableDescription) (mcp.PromptMessage, error) {
+	jsonData, err := json.MarshalIndent(description, "", "  ")
+	if err != nil {
+		return mcp.PromptMessage{}, fmt.Errorf("failed to marshal description: %w", err)
+	}
+
+	return mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+		URI:      TableURI(database, name),
+		MIMEType: "application/json",
+		Text:     string(jsonData),
+	})), nil
+}
+
+// writeSchemaSummary writes one line per table: name(col type, col type?).
+func writeSchemaSummary(b *strings.Builder, tables []types.Table) {
+	for i, t := range tables {
+		if i == summaryMaxTables {
+			fmt.Fprintf(b, "- ... and %d more tables, use scan_database to see them\n", len(tables)-summaryMaxTables)
+			break
+		}
+
+		columns := make([]string, 0, len(t.Columns))
+		for j, c := range t.Columns {
+			if j == summaryMaxColumns {
+				columns = append(columns, fmt.Sprintf("... %d more", len(t.Columns)-summaryMaxColumns))
+				break
+			}
+			column := c.Name + " " + strings.ToLower(c.Type)
+			if c.Nullable {
+				column += "?"
+			}
+			columns = append(columns, column)
+		}
+		name := t.Name
+		if t.Kind != types.KindTable {
+			name += " [" + t.Kind + "]"
+		}
+		fmt.Fprintf(b, "- %s(%s)\n", name, strings.Join(columns, ", "))
+	}
+}
+
+// relevantTables ranks tables by how many words of the question appear in
+// their name (weighted) and column names, returning at most limit names.
+func relevantTables(question string, tables []types.Table, limit int) []string {
+	words := make(map[string]bool)
+	for _, w := range identWords(question) {
+		if len(w) > 2 {
+			words[w] = true
+		}
+	}
+
+	type scored struct {
+		name  string
+		score int
+	}
+	var ranked []scored
+	for _, t := range tables {
+		score := 0
+		for _, w := range identWords(t.Name) {
+			if words[w] {
+				score += 3
+			}
+		}
+		for _, c := range t.Columns {
+			for _, w := range identWords(c.Name) {
+				if words[w] {
+					score++
+				}
+			}
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{name: t.Name, score: score})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	var names []string
+	for i := 0; i < len(ranked) && i < limit; i++ {
+		names = append(names, ranked[i].name)
+	}
+	return names
+}
+
+// identWords splits text or an identifier like order_items into lower case
+// words with a trailing plural s removed, so "orders" matches order_id.
+func identWords(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) > 3 && strings.HasSuffix(f, "s") && !strings.HasSuffix(f, "ss") {
+			f = strings.TrimSuffix(f, "s")
+		}
+		words = append(words, f)
+	}
+	return words
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/databases/catalog"
+	"github.com/melkeydev/mcp-database/types"
+)
+
+// SchemaURI is the resource listing the tables of a database.
+func SchemaURI(database string) string {
+	return fmt.Sprintf("db://%s/schema", url.PathEscape(database))
+}
+
+// TableURI is the resource describing a single table.
+func TableURI(database, table string) string {
+	return fmt.Sprintf("db://%s/table/%s", url.PathEscape(database), url.PathEscape(table))
+}
+
+// SchemaListing is the content of a db://{database}/schema resource.
+type SchemaListing struct {
+	Database string          `json:"database"`
+	Dialect  types.Dialect   `json:"dialect"`
+	Tables   []TableListItem `json:"tables"`
+}
+
+// TableListItem is one table in a SchemaListing.
+type TableListItem struct {
+	catalog.TableRef
+	URI string `json:"uri"`
+}
+
+// ListTableItems returns the tables of conn along with their resource URIs.
+func ListTableItems(ctx context.Context, conn *databases.Connection) ([]TableListItem, error) {
+	refs, err := conn.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TableListItem, 0, len(refs))
+	for _, ref := range refs {
+		items = append(items, TableListItem{
+			TableRef: ref,
+			URI:      TableURI(conn.Name, ref.Ident(conn.Dialect())),
+		})
+	}
+	return items, nil
+}
+
+// SchemaResourceHandler creates a handler for the db://{database}/schema resource
+func SchemaResourceHandler(manager *databases.Manager, database string) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		conn, err := manager.Get(ctx, database)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := ListTableItems(ctx, conn)
+		if err != nil {
+			return nil, fmt.Errorf("list tables failed: %w", err)
+		}
+
+		return jsonResource(request.Params.URI, SchemaListing{
+			Database: conn.Name,
+			Dialect:  conn.Dialect(),
+			Tables:   items,
+		})
+	}
+}
+
+// TableResourceHandler creates a handler for the db://{database}/table/{name}
+// resource template. Sample rows are left out; use sample_table for data.
+func TableResourceHandler(manager *databases.Manager, limits config.LimitsConfig) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		database, err := templateArgument(request, "database")
+		if err != nil {
+			return nil, err
+		}
+		table, err := templateArgument(request, "name")
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := manager.Get(ctx, database)
+		if err != nil {
+			return nil, err
+		}
+
+		_, description, err := conn.DescribeTable(ctx, table, types.DescribeOptions{
+			SkipSampleData: true,
+			CountTimeout:   limits.QueryTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("describe failed: %w", err)
+		}
+
+		return jsonResource(request.Params.URI, description)
+	}
+}
+
+// templateArgument returns a URI template variable, unescaped.
+func templateArgument(request mcp.ReadResourceRequest, key string) (string, error) {
+	var raw string
+	switch v := request.Params.Arguments[key].(type) {
+	case string:
+		raw = v
+	case []string:
+		if len(v) == 1 {
+			raw = v[0]
+		}
+	}
+	if raw == "" {
+		return "", fmt.Errorf("missing %s in resource uri %s", key, request.Params.URI)
+	}
+
+	value, err := url.PathUnescape(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s in resource uri %s: %w", key, request.Params.URI, err)
+	}
+	return value, nil
+}
+
+func jsonResource(uri string, v any) ([]mcp.ResourceContents, error) {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(jsonData),
+		},
+	}, nil
+}
@@ -0,0 +1,203 @@
+// Package history keeps the queries each session ran through
+// query_database, so a client can look up and reuse earlier work.
+//
+// Entries are kept per caller: the authenticated principal on the HTTP
+// transports, otherwise the MCP session. Each caller keeps its most recent
+// entries, and the least recently active callers are forgotten once too
+// many are tracked. With a file configured, entries are also written to a
+// SQLite database and reloaded after a restart.
+package history
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/auth"
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// maxCallers bounds how many callers' histories are held in memory.
+const maxCallers = 1000
+
+// Entry is one query_database call.
+type Entry struct {
+	// ID numbers the caller's queries from 1
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Database   string    `json:"database"`
+	Query      string    `json:"query"`
+	RowCount   *int      `json:"row_count,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Database string
+	// Search matches entries whose query contains it, ignoring case
+	Search string
+	// Failed keeps only queries that returned an error
+	Failed bool
+	// Limit keeps the most recent entries; 0 keeps all
+	Limit int
+}
+
+func (f Filter) matches(e Entry) bool {
+	switch {
+	case f.Database != "" && e.Database != f.Database:
+		return false
+	case f.Search != "" && !strings.Contains(strings.ToLower(e.Query), strings.ToLower(f.Search)):
+		return false
+	case f.Failed && e.Error == "":
+		return false
+	}
+	return true
+}
+
+// History holds the recent queries of every caller.
+type History struct {
+	maxEntries int
+	store      *store
+
+	mu      sync.Mutex
+	callers map[string]*callerHistory
+}
+
+type callerHistory struct {
+	entries  []Entry
+	nextID   int64
+	lastUsed time.Time
+}
+
+// New creates a history keeping cfg.MaxEntries queries per caller, backed
+// by cfg.File when set.
+func New(cfg config.HistoryConfig) (*History, error) {
+	h := &History{
+		maxEntries: cfg.MaxEntries,
+		callers:    make(map[string]*callerHistory),
+	}
+	if cfg.File != "" {
+		s, err := openStore(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		h.store = s
+	}
+	return h, nil
+}
+
+// Close closes the backing file, if any.
+func (h *History) Close() error {
+	if h.store == nil {
+		return nil
+	}
+	return h.store.close()
+}
+
+// Add records entry for the caller of ctx and returns it with its ID set.
+func (h *History) Add(ctx context.Context, entry Entry) Entry {
+	key := callerKey(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := h.caller(key)
+	c.nextID++
+	entry.ID = c.nextID
+	c.entries = append(c.entries, entry)
+	if len(c.entries) > h.maxEntries {
+		c.entries = append(c.entries[:0], c.entries[len(c.entries)-h.maxEntries:]...)
+	}
+
+	if h.store != nil {
+		if err := h.store.add(key, entry, h.maxEntries); err != nil {
+			logStoreError(err)
+		}
+	}
+	return entry
+}
+
+// List returns the caller's entries matching filter, newest first, and the
+// number of entries held for the caller.
+func (h *History) List(ctx context.Context, filter Filter) ([]Entry, int) {
+	key := callerKey(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := h.caller(key)
+	var matched []Entry
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		if !filter.matches(c.entries[i]) {
+			continue
+		}
+		matched = append(matched, c.entries[i])
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+	}
+	return matched, len(c.entries)
+}
+
+// caller returns the history of key, loading it from the store the first
+// time. h.mu must be held.
+func (h *History) caller(key string) *callerHistory {
+	c, ok := h.callers[key]
+	if !ok {
+		h.evict()
+		c = &callerHistory{}
+		if h.store != nil {
+			entries, err := h.store.load(key, h.maxEntries)
+			if err != nil {
+				logStoreError(err)
+			}
+			c.entries = entries
+			if len(entries) > 0 {
+				c.nextID = entries[len(entries)-1].ID
+			}
+		}
+		h.callers[key] = c
+	}
+	c.lastUsed = time.Now()
+	return c
+}
+
+// evict forgets the least recently active callers once maxCallers are
+// held. Their entries stay in the store. h.mu must be held.
+func (h *History) evict() {
+	if len(h.callers) < maxCallers {
+		return
+	}
+
+	keys := make([]string, 0, len(h.callers))
+	for key := range h.callers {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return h.callers[keys[i]].lastUsed.Before(h.callers[keys[j]].lastUsed)
+	})
+	for _, key := range keys[:len(keys)-maxCallers+1] {
+		delete(h.callers, key)
+	}
+}
+
+// callerKey identifies whose history a request belongs to.
+func callerKey(ctx context.Context) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return "principal:" + p.String()
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return "session:" + session.SessionID()
+	}
+	return "session:"
+}
+
+func logStoreError(err error) {
+	slog.Warn("query history file error", "error", err)
+}
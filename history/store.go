@@ -0,0 +1,121 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const createHistoryTable = `
+CREATE TABLE IF NOT EXISTS query_history (
+	caller      TEXT NOT NULL,
+	id          INTEGER NOT NULL,
+	time        TEXT NOT NULL,
+	database    TEXT NOT NULL,
+	query       TEXT NOT NULL,
+	row_count   INTEGER,
+	truncated   INTEGER NOT NULL DEFAULT 0,
+	duration_ms REAL NOT NULL,
+	error       TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (caller, id)
+);
+`
+
+// store persists entries in a SQLite database.
+type store struct {
+	db *sqlx.DB
+}
+
+func openStore(path string) (*store, error) {
+	db, err := sqlx.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open query history file: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(createHistoryTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create query history table: %w", err)
+	}
+	return &store{db: db}, nil
+}
+
+func (s *store) close() error {
+	return s.db.Close()
+}
+
+// add inserts entry and deletes the caller's entries beyond the newest
+// maxEntries.
+func (s *store) add(caller string, entry Entry, maxEntries int) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO query_history (caller, id, time, database, query, row_count, truncated, duration_ms, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		caller, entry.ID, entry.Time.UTC().Format(time.RFC3339Nano), entry.Database, entry.Query,
+		entry.RowCount, entry.Truncated, entry.DurationMS, entry.Error,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM query_history WHERE caller = ? AND id <= ?`, caller, entry.ID-int64(maxEntries))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type entryRow struct {
+	ID         int64         `db:"id"`
+	Time       string        `db:"time"`
+	Database   string        `db:"database"`
+	Query      string        `db:"query"`
+	RowCount   sql.NullInt64 `db:"row_count"`
+	Truncated  bool          `db:"truncated"`
+	DurationMS float64       `db:"duration_ms"`
+	Error      string        `db:"error"`
+}
+
+// load returns the caller's newest maxEntries entries, oldest first.
+func (s *store) load(caller string, maxEntries int) ([]Entry, error) {
+	var rows []entryRow
+	err := s.db.Select(&rows, `
+		SELECT id, time, database, query, row_count, truncated, duration_ms, error
+		FROM (SELECT * FROM query_history WHERE caller = ? ORDER BY id DESC LIMIT ?)
+		ORDER BY id`,
+		caller, maxEntries,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entry := Entry{
+			ID:         row.ID,
+			Database:   row.Database,
+			Query:      row.Query,
+			Truncated:  row.Truncated,
+			DurationMS: row.DurationMS,
+			Error:      row.Error,
+		}
+		entry.Time, err = time.Parse(time.RFC3339Nano, row.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query history time %q: %w", row.Time, err)
+		}
+		if row.RowCount.Valid {
+			n := int(row.RowCount.Int64)
+			entry.RowCount = &n
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
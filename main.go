@@ -1,50 +1,108 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/audit"
+	"github.com/melkeydev/mcp-database/auth"
 	"github.com/melkeydev/mcp-database/config"
 	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/handlers"
+	"github.com/melkeydev/mcp-database/history"
 	"github.com/melkeydev/mcp-database/mcp"
+	"github.com/melkeydev/mcp-database/redact"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := runAudit(os.Args[2:]); err != nil {
+			slog.Error("audit query failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	configPath := flag.String("config", "config.yaml", "path to config file")
+	transport := flag.String("transport", "", "transport to serve on: stdio, sse or streamable-http (overrides server.transport)")
 	flag.Parse()
 
 	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		slog.Error("config error", "error", err)
+		return
+	}
+	if *transport != "" {
+		cfg.Server.Transport = *transport
+	}
+
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		slog.Error("auth config error", "error", err)
+		return
+	}
+
+	policy, err := redact.New(cfg.Redaction)
+	if err != nil {
+		slog.Error("redaction config error", "error", err)
+		return
 	}
 
-	connStr, err := cfg.Database.GetConnectionString()
+	auditLog, err := audit.New(cfg.Audit)
 	if err != nil {
-		slog.Error("connection string error", "error", err)
+		slog.Error("audit config error", "error", err)
+		return
 	}
+	defer auditLog.Close()
 
-	connector, err := databases.NewConnector(cfg.Database.DBType, connStr)
+	hist, err := history.New(cfg.History)
 	if err != nil {
-		slog.Error("failed to create connector", "error", err)
+		slog.Error("query history config error", "error", err)
+		return
+	}
+	defer hist.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Other databases are opened on first use; the default is opened up
+	// front so a broken config fails at startup
+	manager := databases.NewManager(cfg)
+	defer manager.Close()
+
+	if _, err := manager.Get(ctx, ""); err != nil {
+		slog.Error("failed to connect to default database", "error", err)
 		return
 	}
 
 	// Create a new MCP server
+	hooks := &server.Hooks{}
+	calls := mcp.TrackCalls(hooks)
 	s := server.NewMCPServer(
 		"mcp-database",
 		"0.0.1", // TODO: move this to constant
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(false, false),
+		server.WithPromptCapabilities(false),
+		server.WithPaginationLimit(cfg.Server.PageSize),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(calls.Middleware),
+		server.WithToolHandlerMiddleware(auditLog.Middleware),
 		server.WithLogging(),
 	)
 
-	mcp.RegisterTools(s, connector)
+	mcp.RegisterTools(s, manager, cfg.Limits, policy, hist)
+	mcp.RegisterResources(s, hooks, manager, cfg.Limits, hist)
+	mcp.RegisterPrompts(s, manager, cfg.Limits, policy)
 	slog.Info("Info", "connected!", true)
 
-	// Start the stdio server
-	if err := server.ServeStdio(s); err != nil {
-		fmt.Printf("Server error: %v\n", err)
+	// Serve until the client disconnects or a signal arrives
+	if err := mcp.Serve(ctx, s, cfg.Server, authenticator, handlers.NewCompleter(manager), calls); err != nil {
+		slog.Error("server error", "error", err)
 	}
-
 }
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	goMCP "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mcp-go v0.32 ignores notifications/cancelled, so a tool call (and the
+// query behind it) keeps running after the client gives up. Calls are
+// tracked here by session and request ID and their contexts are cancelled
+// when the notification arrives.
+const methodNotificationCancelled = "notifications/cancelled"
+
+// callKeyField carries a call's key from the BeforeCallTool hook, which
+// sees the request ID, to the tool middleware, which owns the context.
+const callKeyField = "mcp-database/call"
+
+// stdioSessionID is the ID of the single session mcp-go's stdio transport
+// serves.
+const stdioSessionID = "stdio"
+
+// CallTracker lets running tool calls be cancelled by request ID.
+type CallTracker struct {
+	mu    sync.Mutex
+	calls map[string]context.CancelFunc
+}
+
+// TrackCalls creates a tracker for the tool calls made through s. The
+// tracker's Middleware must be installed with server.WithToolHandlerMiddleware.
+func TrackCalls(hooks *server.Hooks) *CallTracker {
+	t := &CallTracker{calls: make(map[string]context.CancelFunc)}
+	hooks.AddBeforeCallTool(t.tag)
+	return t
+}
+
+// tag records the call's key in the request's metadata.
+func (t *CallTracker) tag(ctx context.Context, id any, request *goMCP.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &goMCP.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[callKeyField] = callKey(sessionID(ctx), id)
+}
+
+// Middleware runs each tool call with a context that cancel can stop.
+func (t *CallTracker) Middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request goMCP.CallToolRequest) (*goMCP.CallToolResult, error) {
+		if request.Params.Meta == nil {
+			return next(ctx, request)
+		}
+		key, ok := request.Params.Meta.AdditionalFields[callKeyField].(string)
+		if !ok {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		t.mu.Lock()
+		t.calls[key] = cancel
+		t.mu.Unlock()
+
+		defer func() {
+			t.mu.Lock()
+			delete(t.calls, key)
+			t.mu.Unlock()
+			cancel()
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// cancel stops the call with the given request ID, if it is still running.
+func (t *CallTracker) cancel(session string, id any) {
+	t.mu.Lock()
+	cancel, ok := t.calls[callKey(session, id)]
+	t.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// handleCancelled is the notifications/cancelled handler. The HTTP
+// transports dispatch notifications while other requests are running; the
+// stdio transport does not, so its interceptor calls cancel directly.
+func (t *CallTracker) handleCancelled(ctx context.Context, notification goMCP.JSONRPCNotification) {
+	if id, ok := notification.Params.AdditionalFields["requestId"]; ok {
+		t.cancel(sessionID(ctx), id)
+	}
+}
+
+// cancelledRequest returns the request ID named by a notifications/cancelled
+// message.
+func cancelledRequest(request *rpcMessage) (any, bool) {
+	if request.Method != methodNotificationCancelled {
+		return nil, false
+	}
+
+	var params struct {
+		RequestID any `json:"requestId"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.RequestID == nil {
+		return nil, false
+	}
+	return params.RequestID, true
+}
+
+// callKey identifies a request within a session. IDs are compared in their
+// JSON form so 1 and "1" stay distinct.
+func callKey(session string, id any) string {
+	encoded, _ := json.Marshal(id)
+	return session + "\x00" + string(encoded)
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
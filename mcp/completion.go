@@ -0,0 +1,265 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	goMCP "github.com/mark3labs/mcp-go/mcp"
+	"github.com/melkeydev/mcp-database/handlers"
+)
+
+// mcp-go v0.32 defines the completion types but neither dispatches
+// completion/complete nor advertises the completions capability. Until it
+// does, completion requests are answered here before messages reach the
+// MCPServer, and the capability is added to the initialize result on the
+// way out. This works for the stdio and streamable-http transports; the sse
+// transport sends responses on its event stream and is left as is.
+const methodCompletionComplete = "completion/complete"
+
+// completionInterceptor also forwards stdio cancellations to calls.
+type completionInterceptor struct {
+	completer *handlers.Completer
+	calls     *CallTracker
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// parse returns msg as a completion request, or false for any other message.
+func (i *completionInterceptor) parse(msg []byte) (*rpcMessage, bool) {
+	var request rpcMessage
+	if err := json.Unmarshal(msg, &request); err != nil || request.Method != methodCompletionComplete || request.ID == nil {
+		return nil, false
+	}
+	return &request, true
+}
+
+// answer returns the encoded response to a completion request.
+func (i *completionInterceptor) answer(ctx context.Context, request *rpcMessage) []byte {
+	response := rpcMessage{JSONRPC: goMCP.JSONRPC_VERSION, ID: request.ID}
+
+	var params struct {
+		goMCP.CompleteParams
+		// Context carries arguments the client has already resolved
+		Context struct {
+			Arguments map[string]string `json:"arguments"`
+		} `json:"context"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		response.Error = &rpcError{Code: goMCP.INVALID_PARAMS, Message: err.Error()}
+	} else {
+		var completeRequest goMCP.CompleteRequest
+		completeRequest.Params = params.CompleteParams
+		result, err := i.completer.Complete(ctx, completeRequest, params.Context.Arguments)
+		if err != nil {
+			response.Error = &rpcError{Code: goMCP.INTERNAL_ERROR, Message: err.Error()}
+		} else {
+			response.Result = result
+		}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		data, _ = json.Marshal(rpcMessage{
+			JSONRPC: goMCP.JSONRPC_VERSION,
+			ID:      request.ID,
+			Error:   &rpcError{Code: goMCP.INTERNAL_ERROR, Message: err.Error()},
+		})
+	}
+	return data
+}
+
+// addCompletionsCapability adds the completions capability to an initialize
+// result. Any other message is returned unchanged.
+func addCompletionsCapability(msg []byte) []byte {
+	if !bytes.Contains(msg, []byte(`"serverInfo"`)) {
+		return msg
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["result"], &result); err != nil || result["serverInfo"] == nil {
+		return msg
+	}
+	var capabilities map[string]json.RawMessage
+	if err := json.Unmarshal(result["capabilities"], &capabilities); err != nil || capabilities == nil {
+		return msg
+	}
+
+	capabilities["completions"] = json.RawMessage(`{}`)
+	var err error
+	if result["capabilities"], err = json.Marshal(capabilities); err != nil {
+		return msg
+	}
+	if envelope["result"], err = json.Marshal(result); err != nil {
+		return msg
+	}
+	patched, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+
+	if bytes.HasSuffix(msg, []byte("\n")) {
+		patched = append(patched, '\n')
+	}
+	return patched
+}
+
+// stdioQueueSize is how many input lines may wait for the stdio server,
+// which handles one message at a time, before reading input blocks.
+const stdioQueueSize = 64
+
+// stdio splits completion requests off the newline-delimited input and
+// returns the reader and writer for the stdio server to use instead of in
+// and out. Cancellation notifications are acted on as soon as they are
+// read, since the stdio server only sees them once the call they cancel
+// has finished.
+func (i *completionInterceptor) stdio(ctx context.Context, in io.Reader, out io.Writer) (io.Reader, io.Writer) {
+	writer := &stdioWriter{w: out}
+	pr, pw := io.Pipe()
+
+	pending := make(chan []byte, stdioQueueSize)
+	var readErr error
+	go func() {
+		failed := false
+		for line := range pending {
+			if !failed {
+				_, err := pw.Write(line)
+				failed = err != nil
+			}
+		}
+		pw.CloseWithError(readErr)
+	}()
+
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if request, ok := i.parse(line); ok {
+					// Answered concurrently so a slow catalog read does not
+					// hold up other requests
+					go func() {
+						writer.Write(append(i.answer(ctx, request), '\n'))
+					}()
+				} else {
+					i.cancelFromStdio(line)
+					pending <- line
+				}
+			}
+			if err != nil {
+				readErr = err
+				close(pending)
+				return
+			}
+		}
+	}()
+
+	return pr, writer
+}
+
+// cancelFromStdio cancels the call named by a notifications/cancelled line.
+func (i *completionInterceptor) cancelFromStdio(line []byte) {
+	if i.calls == nil {
+		return
+	}
+
+	var message rpcMessage
+	if err := json.Unmarshal(line, &message); err != nil {
+		return
+	}
+	if id, ok := cancelledRequest(&message); ok {
+		i.calls.cancel(stdioSessionID, id)
+	}
+}
+
+// stdioWriter serializes writes from the stdio server and the interceptor
+// and patches the initialize result.
+type stdioWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *stdioWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.w.Write(addCompletionsCapability(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// middleware answers completion requests posted to the streamable HTTP
+// endpoint and patches initialize responses.
+func (i *completionInterceptor) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if request, ok := i.parse(body); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(i.answer(r.Context(), request))
+			return
+		}
+
+		var request rpcMessage
+		if json.Unmarshal(body, &request) != nil || request.Method != string(goMCP.MethodInitialize) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		out := recorder.body.Bytes()
+		if recorder.header.Get("Content-Type") == "application/json" {
+			out = addCompletionsCapability(out)
+			recorder.header.Del("Content-Length")
+		}
+		for key, values := range recorder.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorder.status)
+		w.Write(out)
+	})
+}
+
+// bufferedResponse holds a response so it can be rewritten before sending.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	goMCP "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/handlers"
+	"github.com/melkeydev/mcp-database/redact"
+)
+
+// RegisterPrompts adds prompt templates that start a session with the tool
+// usage guide and the relevant schema already in context.
+func RegisterPrompts(s *server.MCPServer, manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy) {
+	databaseArg := goMCP.WithArgument("database",
+		goMCP.ArgumentDescription(fmt.Sprintf("Database to use (%s). Default: %s", strings.Join(manager.Names(), ", "), manager.Default())),
+	)
+
+	explorePrompt := goMCP.NewPrompt("explore_database",
+		goMCP.WithPromptDescription("Start exploring a database: the tool usage guide plus a compact summary of every table and its columns"),
+		databaseArg,
+	)
+
+	analyzeTablePrompt := goMCP.NewPrompt("analyze_table",
+		goMCP.WithPromptDescription("Profile a single table: meaning, data quality and distributions. Attaches the table's description and sample rows"),
+		goMCP.WithArgument("table",
+			goMCP.RequiredArgument(),
+			goMCP.ArgumentDescription("Table to analyze, optionally schema-qualified (sales.orders)"),
+		),
+		databaseArg,
+	)
+
+	investigateMetricPrompt := goMCP.NewPrompt("investigate_metric",
+		goMCP.WithPromptDescription("Answer a business question with SQL. Attaches the descriptions of the tables that best match the question"),
+		goMCP.WithArgument("question",
+			goMCP.RequiredArgument(),
+			goMCP.ArgumentDescription("The question or metric to investigate, e.g. \"Why did weekly revenue drop in March?\""),
+		),
+		goMCP.WithArgument("tables",
+			goMCP.ArgumentDescription("Comma-separated tables to attach instead of picking them from the question"),
+		),
+		databaseArg,
+	)
+
+	s.AddPrompt(explorePrompt, handlers.ExplorePromptHandler(manager, GetToolUsageGuide()))
+	s.AddPrompt(analyzeTablePrompt, handlers.AnalyzeTablePromptHandler(manager, limits, policy))
+	s.AddPrompt(investigateMetricPrompt, handlers.InvestigateMetricPromptHandler(manager, limits))
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	goMCP "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/databases"
+	"github.com/melkeydev/mcp-database/handlers"
+	"github.com/melkeydev/mcp-database/history"
+)
+
+// RegisterResources exposes each database's schema as MCP resources:
+//
+//	db://{database}/schema          tables and views in the database
+//	db://{database}/table/{name}    the TableDescription of one table
+//	db://{database}/diagram         a Mermaid ER diagram of the database
+//	db://{database}/diagram/{table} a Mermaid ER diagram around one table
+//	db://history                    the session's query_database history
+//
+// Table resources are also listed individually by resources/list. The
+// catalog is re-read before each listing so the list follows schema
+// changes; pagination is handled by the server's pagination limit.
+func RegisterResources(s *server.MCPServer, hooks *server.Hooks, manager *databases.Manager, limits config.LimitsConfig, hist *history.History) {
+	for _, name := range manager.Names() {
+		schemaResource := goMCP.NewResource(handlers.SchemaURI(name), name,
+			goMCP.WithResourceDescription(fmt.Sprintf("Tables and views in the %s database", name)),
+			goMCP.WithMIMEType("application/json"),
+		)
+		s.AddResource(schemaResource, handlers.SchemaResourceHandler(manager, name))
+
+		diagramResource := goMCP.NewResource(handlers.DiagramURI(name), name+" diagram",
+			goMCP.WithResourceDescription(fmt.Sprintf("Mermaid ER diagram of the tables in the %s database", name)),
+			goMCP.WithMIMEType("text/plain"),
+		)
+		s.AddResource(diagramResource, handlers.DiagramResourceHandler(manager, name))
+	}
+
+	historyResource := goMCP.NewResource(handlers.HistoryURI, "history",
+		goMCP.WithResourceDescription("Queries this session ran with query_database, newest first"),
+		goMCP.WithMIMEType("application/json"),
+	)
+	s.AddResource(historyResource, handlers.HistoryResourceHandler(hist))
+
+	tableTemplate := goMCP.NewResourceTemplate("db://{database}/table/{name}", "table",
+		goMCP.WithTemplateDescription("Columns, row count, primary keys and indexes of a table. name may be schema-qualified, e.g. sales.orders"),
+		goMCP.WithTemplateMIMEType("application/json"),
+	)
+	s.AddResourceTemplate(tableTemplate, handlers.TableResourceHandler(manager, limits))
+
+	diagramTemplate := goMCP.NewResourceTemplate("db://{database}/diagram/{table}", "table diagram",
+		goMCP.WithTemplateDescription("Mermaid ER diagram of a table and the tables it is directly related to"),
+		goMCP.WithTemplateMIMEType("text/plain"),
+	)
+	s.AddResourceTemplate(diagramTemplate, handlers.TableDiagramResourceHandler(manager))
+
+	tables := &tableResources{
+		server:     s,
+		manager:    manager,
+		limits:     limits,
+		registered: make(map[string]map[string]bool),
+	}
+	hooks.AddBeforeListResources(func(ctx context.Context, id any, message *goMCP.ListResourcesRequest) {
+		tables.sync(ctx)
+	})
+}
+
+// tableResources keeps one registered resource per catalog table.
+type tableResources struct {
+	server  *server.MCPServer
+	manager *databases.Manager
+	limits  config.LimitsConfig
+
+	mu sync.Mutex
+	// registered holds the table resource URIs per database
+	registered map[string]map[string]bool
+}
+
+// sync registers resources for new tables and removes those of dropped
+// tables. A database that cannot be read keeps its previous resources.
+func (t *tableResources) sync(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	readTable := handlers.TableResourceHandler(t.manager, t.limits)
+
+	for _, name := range t.manager.Names() {
+		conn, err := t.manager.Get(ctx, name)
+		if err != nil {
+			slog.Warn("skipping table resources", "database", name, "error", err)
+			continue
+		}
+
+		items, err := handlers.ListTableItems(ctx, conn)
+		if err != nil {
+			slog.Warn("skipping table resources", "database", name, "error", err)
+			continue
+		}
+
+		want := make(map[string]bool, len(items))
+		var added []server.ServerResource
+		for _, item := range items {
+			want[item.URI] = true
+			if t.registered[name][item.URI] {
+				continue
+			}
+
+			ident := item.Ident(conn.Dialect())
+			kind := item.Kind()
+			kind = strings.ToUpper(kind[:1]) + kind[1:]
+			arguments := map[string]any{
+				"database": url.PathEscape(name),
+				"name":     url.PathEscape(ident),
+			}
+			added = append(added, server.ServerResource{
+				Resource: goMCP.NewResource(item.URI, name+"/"+ident,
+					goMCP.WithResourceDescription(fmt.Sprintf("%s %s in the %s database", kind, ident, name)),
+					goMCP.WithMIMEType("application/json"),
+				),
+				Handler: func(ctx context.Context, request goMCP.ReadResourceRequest) ([]goMCP.ResourceContents, error) {
+					request.Params.Arguments = arguments
+					return readTable(ctx, request)
+				},
+			})
+		}
+
+		for uri := range t.registered[name] {
+			if !want[uri] {
+				t.server.RemoveResource(uri)
+			}
+		}
+		if len(added) > 0 {
+			t.server.AddResources(added...)
+		}
+		t.registered[name] = want
+	}
+}
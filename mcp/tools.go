@@ -1,23 +1,51 @@
 package mcp
 
 import (
+	"fmt"
+	"strings"
+
 	goMCP "github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/config"
 	"github.com/melkeydev/mcp-database/databases"
 	"github.com/melkeydev/mcp-database/handlers"
+	"github.com/melkeydev/mcp-database/history"
+	"github.com/melkeydev/mcp-database/redact"
 )
 
-func RegisterTools(s *server.MCPServer, connector databases.DatabaseConnector) {
+func RegisterTools(s *server.MCPServer, manager *databases.Manager, limits config.LimitsConfig, policy *redact.Policy, hist *history.History) {
+	databaseArg := goMCP.WithString("database",
+		goMCP.Description(fmt.Sprintf("Name of the database to use (%s). Default: %s. See list_databases", strings.Join(manager.Names(), ", "), manager.Default())),
+	)
+
+	// List databases tool - Use to see which connections are configured
+	listDatabasesTool := goMCP.NewTool("list_databases",
+		goMCP.WithDescription(`List the databases configured on this server with their dialect and whether they can be reached.
+Pass a name from this list as the "database" argument of the other tools. Calls without it use the default database.`),
+	)
+
 	// Scan tool - Use this FIRST to discover available tables
 	scanTool := goMCP.NewTool("scan_database",
-		goMCP.WithDescription(`Discover database tables and their structure. Use this tool FIRST before querying to understand the database schema.
-Returns a list of tables with their columns, data types, and nullable information.
+		goMCP.WithDescription(`Discover database tables and views and their structure. Use this tool FIRST before querying to understand the database schema.
+Returns a list of tables with their kind (table, view or materialized view), columns, data types, and nullable information. Views can be sampled and queried like tables.
+Requested tables that do not exist are listed under "not_found", with a suggested name where one is close.
 Examples:
 - Scan all tables: tables=""
-- Scan specific tables: tables="users,orders,products"`),
+- Scan specific tables: tables="users,orders,products"
+- Schema-qualified tables: tables="sales.orders"
+- Glob patterns: tables="order_*"`),
 		goMCP.WithString("tables",
-			goMCP.Description("Comma-separated list of table names to scan. Leave empty to scan all tables. Example: 'users,orders' or empty string for all"),
+			goMCP.Description("Comma-separated list (or JSON array) of table or view names to scan. Names may be schema-qualified (sales.orders) and may use * and ? wildcards (order_*). Leave empty to scan all tables"),
 		),
+		databaseArg,
+	)
+
+	// List schemas tool - Use to see which schemas hold tables
+	listSchemasTool := goMCP.NewTool("list_schemas",
+		goMCP.WithDescription(`List the schemas visible to this server with the number of tables in each.
+For MySQL these are databases, for SQLite attached databases. System schemas are hidden.
+The default schema is where unqualified table names are looked up.`),
+		databaseArg,
 	)
 
 	// Sample tool - Use to preview table data
@@ -27,14 +55,163 @@ Returns actual row data from the specified table.
 Use scan_database first to discover available tables.
 Examples:
 - Sample 10 rows: table="users", limit=10
-- Sample default rows: table="products" (defaults to 10 rows)`),
+- Sample default rows: table="products" (defaults to 10 rows)
+- Selected columns, newest first: table="orders", columns="id,total,created_at", order_by="created_at DESC"
+- Filtered: table="orders", where="status = 'refunded'"
+- Random rows instead of the first physical rows: table="events", random=true`),
 		goMCP.WithString("table",
 			goMCP.Required(),
 			goMCP.Description("Exact name of the table to sample (case-sensitive). Get table names from scan_database first"),
 		),
 		goMCP.WithNumber("limit",
-			goMCP.Description("Number of rows to return. Default: 10, Maximum recommended: 100"),
+			goMCP.Description(fmt.Sprintf("Number of rows to return. Default: %d, Maximum: %d", limits.SampleDefaultRows, limits.SampleMaxRows)),
+		),
+		goMCP.WithString("columns",
+			goMCP.Description("Comma-separated list of columns to return. Default: all columns"),
+		),
+		goMCP.WithString("order_by",
+			goMCP.Description("Comma-separated list of 'column [ASC|DESC]' to sort by. Cannot be combined with random"),
+		),
+		goMCP.WithString("where",
+			goMCP.Description("SQL boolean expression to filter rows, e.g. \"status = 'active' AND total > 100\". Must be read-only"),
+		),
+		goMCP.WithBoolean("random",
+			goMCP.Description("Return a random sample instead of the first rows. Default: false"),
+		),
+		databaseArg,
+	)
+
+	// Describe tool - Use to inspect a single table in depth
+	describeTool := goMCP.NewTool("describe_table",
+		goMCP.WithDescription(`Get a detailed description of a single table or view: columns, row count, primary keys, indexes, relationships and a few sample rows.
+Use describe_object for the definition of a view.
+Use scan_database first to discover available tables.
+Examples:
+- Full description: table="users"
+- PostgreSQL table outside the public schema: table="sales.orders"
+- Structure only: table="events", skip_row_count=true, skip_sample_data=true`),
+		goMCP.WithString("table",
+			goMCP.Required(),
+			goMCP.Description("Exact name of the table to describe (case-sensitive). For PostgreSQL use schema.table for tables outside the public schema"),
+		),
+		goMCP.WithBoolean("skip_row_count",
+			goMCP.Description("Skip the COUNT(*) query. Recommended for very large tables. Default: false"),
+		),
+		goMCP.WithBoolean("skip_sample_data",
+			goMCP.Description("Skip fetching sample rows. Default: false"),
+		),
+		databaseArg,
+	)
+
+	// List relationships tool - Use to find join conditions
+	listRelationshipsTool := goMCP.NewTool("list_relationships",
+		goMCP.WithDescription(`List the foreign keys between tables, i.e. which columns can be joined to which.
+Relationships marked "inferred" are not declared in the database but guessed from column names: a customer_id column is taken to reference customers.id. Check them with sample_table before relying on them.
+Examples:
+- All relationships: tables=""
+- Relationships from or to orders: tables="orders"
+- Declared foreign keys only: include_inferred=false`),
+		goMCP.WithString("tables",
+			goMCP.Description("Comma-separated list (or JSON array) of tables. Only relationships from or to these tables are returned. Leave empty for all tables"),
+		),
+		goMCP.WithBoolean("include_inferred",
+			goMCP.Description("Include relationships inferred from *_id column names. Default: true"),
+		),
+		databaseArg,
+	)
+
+	// Find join path tool - Use to write joins across several tables
+	findJoinPathTool := goMCP.NewTool("find_join_path",
+		goMCP.WithDescription(`Suggest how to join two or more tables. Follows foreign keys, through intermediate tables where needed, and returns the shortest join paths as a FROM clause with JOIN ... ON ... conditions ready to paste into a query.
+Paths are ranked by number of joins, then by how many joins go from a referenced key to the referencing table (one-to-many joins, which can repeat rows), then by how many use inferred relationships.
+Examples:
+- Two tables: tables="customers,products"
+- Several tables: tables="customers,products,warehouses"
+- Also use relationships guessed from *_id columns: tables="orders,coupons", include_inferred=true`),
+		goMCP.WithString("tables",
+			goMCP.Required(),
+			goMCP.Description("Comma-separated list (or JSON array) of at least two tables to join. The first table goes in the FROM clause"),
+		),
+		goMCP.WithBoolean("include_inferred",
+			goMCP.Description("Also follow relationships inferred from *_id column names. Default: false"),
+		),
+		goMCP.WithNumber("max_paths",
+			goMCP.Description("Number of alternative paths to return. Default: 3"),
+		),
+		databaseArg,
+	)
+
+	// Schema diagram tool - Draw tables and their relationships
+	schemaDiagramTool := goMCP.NewTool("schema_diagram",
+		goMCP.WithDescription(`Draw an entity-relationship diagram of tables with their columns, primary keys and foreign keys, as Mermaid erDiagram or Graphviz DOT text.
+Relationships inferred from column names are drawn dashed. Name the tables to draw, or a focus table to draw it with the tables around it; diagrams of every table are limited to small schemas.
+Examples:
+- Chosen tables: tables="customers,orders,order_items"
+- Around a table: focus="orders", hops=2
+- Graphviz: focus="orders", format="dot"`),
+		goMCP.WithString("format",
+			goMCP.Description("Diagram syntax: mermaid or dot. Default: mermaid"),
+			goMCP.Enum("mermaid", "dot"),
+		),
+		goMCP.WithString("tables",
+			goMCP.Description("Comma-separated list (or JSON array) of tables to draw"),
+		),
+		goMCP.WithString("focus",
+			goMCP.Description("Draw this table and the tables within hops relationships of it"),
+		),
+		goMCP.WithNumber("hops",
+			goMCP.Description("How many relationships away from focus to include. Default: 1"),
+		),
+		goMCP.WithBoolean("include_inferred",
+			goMCP.Description("Include relationships inferred from *_id column names. Default: true"),
+		),
+		databaseArg,
+	)
+
+	// List objects tool - Find views, routines and triggers
+	listObjectsTool := goMCP.NewTool("list_objects",
+		goMCP.WithDescription(`List the views, materialized views, functions, stored procedures and triggers in the database, without their definitions.
+Views often hold curated business logic, e.g. how revenue or active customers are computed; prefer them over re-deriving that logic from base tables.
+SQLite has views and triggers only; materialized views are PostgreSQL only.
+Examples:
+- Everything: kinds=""
+- Views only: kinds="view,materialized view"
+- Routines: kinds="function,procedure"`),
+		goMCP.WithString("kinds",
+			goMCP.Description("Comma-separated list (or JSON array) of kinds to list: view, materialized view, function, procedure, trigger. Leave empty for all kinds"),
 		),
+		databaseArg,
+	)
+
+	// Describe object tool - Read the definition of a view, routine or trigger
+	describeObjectTool := goMCP.NewTool("describe_object",
+		goMCP.WithDescription(`Get the definition of a view, materialized view, function, stored procedure or trigger, with the signature and language of routines and the table of triggers.
+Several objects are returned when the name is shared, e.g. by overloaded functions; use kind to narrow them down.
+Use list_objects first to discover available objects, and describe_table for the columns of a view.
+Examples:
+- View: name="active_customers"
+- Function outside the default schema: name="billing.invoice_total", kind="function"`),
+		goMCP.WithString("name",
+			goMCP.Required(),
+			goMCP.Description("Name of the object, optionally schema-qualified"),
+		),
+		goMCP.WithString("kind",
+			goMCP.Description("Only describe objects of this kind: view, materialized view, function, procedure or trigger"),
+		),
+		databaseArg,
+	)
+
+	// Refresh schema tool - Use after the schema changed
+	refreshSchemaTool := goMCP.NewTool("refresh_schema",
+		goMCP.WithDescription(`Drop cached schema information so the next call reads it from the database again.
+Table and column lists are cached for a few minutes. Use this after tables were created, dropped or altered.
+Examples:
+- Refresh everything: tables=""
+- Refresh specific tables: tables="users,sales.orders"`),
+		goMCP.WithString("tables",
+			goMCP.Description("Comma-separated list (or JSON array) of tables to refresh. The table list itself is always refreshed. Leave empty to refresh everything"),
+		),
+		databaseArg,
 	)
 
 	// Query tool - Execute SQL queries
@@ -48,13 +225,66 @@ Examples:
 - Aggregate query: "SELECT category, COUNT(*) as count FROM products GROUP BY category"`),
 		goMCP.WithString("query",
 			goMCP.Required(),
-			goMCP.Description("SQL SELECT query to execute. Must be a valid SELECT statement. Other operations (INSERT, UPDATE, DELETE) are not allowed"),
+			goMCP.Description("SQL SELECT query to execute. Must be a single valid SELECT statement. Other operations (INSERT, UPDATE, DELETE, SELECT ... INTO, functions with side effects) are rejected"),
+		),
+		goMCP.WithNumber("max_rows",
+			goMCP.Description(fmt.Sprintf("Maximum number of rows to return. Default and maximum: %d. Results are also cut off after %d bytes; truncated results are marked with truncated=true", limits.MaxRows, limits.MaxResultBytes)),
+		),
+		goMCP.WithNumber("timeout_ms",
+			goMCP.Description(fmt.Sprintf("Abort the query after this many milliseconds. Default and maximum: %d", limits.QueryTimeout.Milliseconds())),
+		),
+		databaseArg,
+	)
+
+	// Explain tool - Check a query's plan before running it
+	explainTool := goMCP.NewTool("explain_query",
+		goMCP.WithDescription(`Show the execution plan of a SELECT query without running it: the operations, the tables and indexes they use, and the planner's row and cost estimates.
+Use it before running a query that may be slow, e.g. one that joins or aggregates large tables. A full scan of a large table usually means a missing filter or index.
+SQLite plans list the steps only, without estimates.
+Example: query="SELECT * FROM orders WHERE customer_id = 42"`),
+		goMCP.WithString("query",
+			goMCP.Required(),
+			goMCP.Description("SQL SELECT query to explain. The same rules as for query_database apply"),
 		),
+		databaseArg,
 	)
 
-	s.AddTool(scanTool, handlers.ScanHandler(connector))
-	s.AddTool(sampleTool, handlers.SampleHandler(connector))
-	s.AddTool(queryTool, handlers.QueryHandler(connector))
+	// Query history tool - Recall earlier queries of this session
+	queryHistoryTool := goMCP.NewTool("query_history",
+		goMCP.WithDescription(`List the queries this session ran with query_database, newest first, with their row counts, durations and errors.
+Use it to reuse or refine earlier queries instead of writing them again.
+Examples:
+- Last 20 queries: (no arguments)
+- Earlier queries on orders: search="orders"
+- Queries that failed: errors_only=true`),
+		goMCP.WithNumber("limit",
+			goMCP.Description("Number of queries to return. Default: 20"),
+		),
+		goMCP.WithString("search",
+			goMCP.Description("Only return queries containing this text, ignoring case"),
+		),
+		goMCP.WithBoolean("errors_only",
+			goMCP.Description("Only return queries that failed. Default: false"),
+		),
+		goMCP.WithString("database",
+			goMCP.Description("Only return queries run against this database"),
+		),
+	)
+
+	s.AddTool(listDatabasesTool, handlers.ListDatabasesHandler(manager))
+	s.AddTool(scanTool, handlers.ScanHandler(manager))
+	s.AddTool(listSchemasTool, handlers.ListSchemasHandler(manager))
+	s.AddTool(sampleTool, handlers.SampleHandler(manager, limits, policy))
+	s.AddTool(describeTool, handlers.DescribeHandler(manager, limits, policy))
+	s.AddTool(listRelationshipsTool, handlers.ListRelationshipsHandler(manager))
+	s.AddTool(findJoinPathTool, handlers.FindJoinPathHandler(manager))
+	s.AddTool(schemaDiagramTool, handlers.SchemaDiagramHandler(manager))
+	s.AddTool(listObjectsTool, handlers.ListObjectsHandler(manager))
+	s.AddTool(describeObjectTool, handlers.DescribeObjectHandler(manager))
+	s.AddTool(refreshSchemaTool, handlers.RefreshSchemaHandler(manager))
+	s.AddTool(queryTool, handlers.QueryHandler(manager, limits, policy, hist))
+	s.AddTool(explainTool, handlers.ExplainHandler(manager, limits))
+	s.AddTool(queryHistoryTool, handlers.QueryHistoryHandler(hist))
 }
 
 // Helper Function
@@ -62,14 +292,21 @@ func GetToolUsageGuide() string {
 	return `
 Database MCP Tools Usage Guide:
 
-1. ALWAYS start with 'scan_database' to discover available tables and their structure
-2. Use 'sample_table' to preview data and understand table contents
-3. Use 'query_database' to execute specific SELECT queries
+1. ALWAYS start with 'scan_database' to discover available tables and views and their structure
+2. Use 'describe_table' to inspect keys, indexes and row counts of a single table
+3. Use 'list_objects' and 'describe_object' to find views, functions, procedures and triggers and read their definitions
+4. Use 'list_relationships' to find the columns to join tables on, or 'find_join_path' to get the JOIN clauses connecting several tables
+5. Use 'sample_table' to preview data and understand table contents
+6. Use 'query_database' to execute specific SELECT queries
+7. Use 'list_databases' to see the configured databases; pass database="name" to any tool to use one other than the default
+8. Use 'refresh_schema' after the schema changed; table and column lists are cached for a few minutes
+9. Use 'explain_query' to check the plan of a query that may be slow before running it
+10. Use 'query_history' to recall queries you already ran in this session before writing them again
 
 Workflow example:
 - First: scan_database (discover schema)
+- Then: describe_table with table="users" (inspect keys and indexes)
 - Then: sample_table with table="users" (preview data)
 - Finally: query_database with query="SELECT * FROM users WHERE created_at > '2024-01-01'"
 `
 }
-
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/melkeydev/mcp-database/auth"
+	"github.com/melkeydev/mcp-database/config"
+	"github.com/melkeydev/mcp-database/handlers"
+)
+
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// httpTransport is implemented by both the SSE and streamable HTTP servers.
+type httpTransport interface {
+	http.Handler
+	Shutdown(ctx context.Context) error
+}
+
+// Serve runs s on the configured transport until ctx is cancelled or the
+// transport fails. HTTP transports are shut down gracefully, waiting up to
+// cfg.ShutdownTimeout for in-flight requests.
+// A non-nil authenticator guards the HTTP transports; completer answers
+// completion requests and calls is told about cancelled requests.
+func Serve(ctx context.Context, s *server.MCPServer, cfg config.ServerConfig, authenticator auth.Authenticator, completer *handlers.Completer, calls *CallTracker) error {
+	completions := &completionInterceptor{completer: completer, calls: calls}
+	s.AddNotificationHandler(methodNotificationCancelled, calls.handleCancelled)
+
+	switch cfg.Transport {
+	case TransportStdio:
+		stdio := server.NewStdioServer(s)
+		stdio.SetErrorLogger(log.New(os.Stderr, "", log.LstdFlags))
+		in, out := completions.stdio(ctx, os.Stdin, os.Stdout)
+		if err := stdio.Listen(ctx, in, out); err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return nil
+	case TransportSSE, TransportStreamableHTTP:
+		return serveHTTP(ctx, s, cfg, authenticator, completions)
+	default:
+		return fmt.Errorf("unsupported transport: %s (expected %s, %s or %s)", cfg.Transport, TransportStdio, TransportSSE, TransportStreamableHTTP)
+	}
+}
+
+func serveHTTP(ctx context.Context, s *server.MCPServer, cfg config.ServerConfig, authenticator auth.Authenticator, completions *completionInterceptor) error {
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:    cfg.Address,
+		Handler: mux,
+	}
+	if authenticator != nil {
+		httpServer.Handler = auth.Middleware(authenticator, mux)
+	} else {
+		slog.Warn("no auth configured, the HTTP transport accepts unauthenticated requests")
+	}
+
+	var transport httpTransport
+	switch cfg.Transport {
+	case TransportSSE:
+		// The SSE server routes <base_path>/sse and <base_path>/message itself
+		transport = server.NewSSEServer(s,
+			server.WithStaticBasePath(cfg.BasePath),
+			server.WithHTTPServer(httpServer),
+			server.WithKeepAlive(true),
+		)
+		mux.Handle("/", transport)
+	default:
+		transport = server.NewStreamableHTTPServer(s,
+			server.WithEndpointPath(cfg.BasePath),
+			server.WithStreamableHTTPServer(httpServer),
+		)
+		mux.Handle(cfg.BasePath, completions.middleware(transport))
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "transport", cfg.Transport, "address", cfg.Address, "base_path", cfg.BasePath)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := transport.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("http server: %w", err)
+	}
+	return nil
+}
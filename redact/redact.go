@@ -0,0 +1,340 @@
+// Package redact masks sensitive values in result rows before they are
+// returned to a client.
+//
+// Column rules match by name, which only helps while the client uses the
+// real column names; value rules look at the data itself and also catch
+// aliased or computed columns. Query results carry no table per column, so
+// there a pattern naming a table applies to every column of that name once
+// the query reads a matching table, or any table that cannot be told apart
+// from one, such as a view.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+// Action is what happens to a matching value.
+type Action string
+
+const (
+	// ActionHash replaces the value with a keyed hash, so equal values can
+	// still be grouped and joined on
+	ActionHash Action = "hash"
+	// ActionMask keeps a few characters, e.g. the last four digits
+	ActionMask Action = "mask"
+	// ActionDrop removes the column from the row
+	ActionDrop Action = "drop"
+)
+
+// detector is a built-in value pattern. check, when set, confirms a match
+// the regular expression alone cannot.
+type detector struct {
+	pattern *regexp.Regexp
+	check   func(string) bool
+}
+
+var detectors = map[string]detector{
+	"email":       {pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	"credit_card": {pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), check: luhn},
+	"jwt":         {pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)},
+}
+
+// Policy applies the configured rules. A nil Policy leaves rows unchanged.
+type Policy struct {
+	columns []columnRule
+	values  []valueRule
+	hashKey []byte
+}
+
+type columnRule struct {
+	patterns []columnPattern
+	action   Action
+}
+
+// columnPattern holds the parts of a column pattern; empty parts match
+// anything.
+type columnPattern struct {
+	schema, table, column string
+}
+
+type valueRule struct {
+	detector
+	action Action
+}
+
+// New compiles the rules in cfg. It returns nil when no rules are
+// configured.
+func New(cfg config.RedactionConfig) (*Policy, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	p := &Policy{hashKey: []byte(cfg.HashKey)}
+	if cfg.HashKeyEnv != "" {
+		key := os.Getenv(cfg.HashKeyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("redaction hash key: environment variable %s is not set", cfg.HashKeyEnv)
+		}
+		p.hashKey = []byte(key)
+	}
+
+	for i, rule := range cfg.Rules {
+		action := Action(rule.Action)
+		switch action {
+		case ActionHash, ActionMask, ActionDrop:
+		default:
+			return nil, fmt.Errorf("redaction rule %d: unknown action %q (expected hash, mask or drop)", i+1, rule.Action)
+		}
+		if len(rule.Columns) == 0 && len(rule.Values) == 0 {
+			return nil, fmt.Errorf("redaction rule %d: needs columns or values", i+1)
+		}
+
+		if len(rule.Columns) > 0 {
+			c := columnRule{action: action}
+			for _, raw := range rule.Columns {
+				pattern, err := parseColumnPattern(raw)
+				if err != nil {
+					return nil, fmt.Errorf("redaction rule %d: %w", i+1, err)
+				}
+				c.patterns = append(c.patterns, pattern)
+			}
+			p.columns = append(p.columns, c)
+		}
+
+		for _, raw := range rule.Values {
+			d, ok := detectors[raw]
+			if !ok {
+				pattern, err := regexp.Compile(raw)
+				if err != nil {
+					return nil, fmt.Errorf("redaction rule %d: invalid value pattern %q: %w", i+1, raw, err)
+				}
+				d = detector{pattern: pattern}
+			}
+			p.values = append(p.values, valueRule{detector: d, action: action})
+		}
+	}
+
+	return p, nil
+}
+
+func parseColumnPattern(raw string) (columnPattern, error) {
+	parts := strings.Split(raw, ".")
+	for _, part := range parts {
+		if part == "" {
+			return columnPattern{}, fmt.Errorf("invalid column pattern %q", raw)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return columnPattern{column: parts[0]}, nil
+	case 2:
+		return columnPattern{table: parts[0], column: parts[1]}, nil
+	case 3:
+		return columnPattern{schema: parts[0], table: parts[1], column: parts[2]}, nil
+	default:
+		return columnPattern{}, fmt.Errorf("invalid column pattern %q: expected column, table.column or schema.table.column", raw)
+	}
+}
+
+// Source is a table rows are read from. Empty parts are unknown and match
+// any pattern.
+type Source struct {
+	Schema, Table string
+}
+
+// Rows redacts rows in place. schema and table name where the rows came
+// from; leave them empty when unknown.
+func (p *Policy) Rows(schema, table string, rows []map[string]any) {
+	p.QueryRows([]Source{{Schema: schema, Table: table}}, rows)
+}
+
+// QueryRows redacts the rows of a query reading sources in place. A pattern
+// naming a table applies to the columns of a matching name when one of the
+// sources matches its table, so a query reading no table is only subject to
+// the unscoped patterns and the value rules.
+func (p *Policy) QueryRows(sources []Source, rows []map[string]any) {
+	if p == nil {
+		return
+	}
+	for _, row := range rows {
+		p.row(sources, row)
+	}
+}
+
+func (p *Policy) row(sources []Source, row map[string]any) {
+	for column, value := range row {
+		if action, ok := p.columnAction(sources, column); ok {
+			if action == ActionDrop {
+				delete(row, column)
+			} else if value != nil {
+				row[column] = p.apply(action, fmt.Sprint(text(value)))
+			}
+			continue
+		}
+
+		s, ok := textValue(value)
+		if !ok {
+			continue
+		}
+		redacted, drop := p.redactText(s)
+		if drop {
+			delete(row, column)
+		} else if redacted != s {
+			row[column] = redacted
+		}
+	}
+}
+
+func (p *Policy) columnAction(sources []Source, column string) (Action, bool) {
+	for _, rule := range p.columns {
+		for _, pattern := range rule.patterns {
+			if !globMatch(pattern.column, column) {
+				continue
+			}
+			for _, source := range sources {
+				if pattern.matches(source) {
+					return rule.action, true
+				}
+			}
+			// an unscoped pattern applies even when nothing is read
+			if pattern.table == "" && pattern.schema == "" {
+				return rule.action, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matches reports whether the table part of the pattern matches source.
+// Unknown schema and table names match.
+func (c columnPattern) matches(source Source) bool {
+	if c.table != "" && source.Table != "" && !globMatch(c.table, source.Table) {
+		return false
+	}
+	if c.schema != "" && source.Schema != "" && !globMatch(c.schema, source.Schema) {
+		return false
+	}
+	return true
+}
+
+// redactText applies the value rules to s. It reports whether a drop rule
+// matched, in which case the whole column goes.
+func (p *Policy) redactText(s string) (string, bool) {
+	for _, rule := range p.values {
+		found := false
+		s = rule.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if rule.check != nil && !rule.check(match) {
+				return match
+			}
+			found = true
+			if rule.action == ActionDrop {
+				return match
+			}
+			return p.apply(rule.action, match)
+		})
+		if found && rule.action == ActionDrop {
+			return "", true
+		}
+	}
+	return s, false
+}
+
+func (p *Policy) apply(action Action, s string) string {
+	switch action {
+	case ActionHash:
+		return p.hash(s)
+	case ActionMask:
+		return mask(s)
+	default:
+		return s
+	}
+}
+
+// hash returns a short, stable token for s.
+func (p *Policy) hash(s string) string {
+	var sum []byte
+	if len(p.hashKey) > 0 {
+		mac := hmac.New(sha256.New, p.hashKey)
+		mac.Write([]byte(s))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(s))
+		sum = digest[:]
+	}
+	return "hash:" + hex.EncodeToString(sum[:8])
+}
+
+// mask keeps the first character and domain of an email address and the
+// last four characters of anything else longer than eight characters.
+func mask(s string) string {
+	if at := strings.LastIndex(s, "@"); at > 0 && !strings.ContainsAny(s, " \t\n") {
+		local := []rune(s[:at])
+		return string(local[0]) + "***" + s[at:]
+	}
+
+	runes := []rune(s)
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return "****" + string(runes[len(runes)-4:])
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by
+// payment card numbers.
+func luhn(s string) bool {
+	sum, digits := 0, 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// textValue returns value as a string when it holds text. MySQL returns
+// text columns as []byte.
+func textValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	default:
+		return "", false
+	}
+}
+
+// text converts []byte to string so it is not formatted as a byte list.
+func text(value any) any {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return value
+}
+
+func globMatch(pattern, name string) bool {
+	ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name))
+	return err == nil && ok
+}
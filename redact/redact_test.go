@@ -0,0 +1,194 @@
+package redact
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/melkeydev/mcp-database/config"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"5500-0000-0000-0004", true},
+		{"378282246310005", true},
+		{"4111111111111112", false},
+		{"1234567890123456", false},
+		{"0000000000", false}, // too short to be a card number
+	}
+	for _, tt := range tests {
+		if got := luhn(tt.digits); got != tt.want {
+			t.Errorf("luhn(%q) = %v, want %v", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"alice@example.com":    "a***@example.com",
+		"a@b.io":               "a***@b.io",
+		"not an@email address": "****ress",
+		"secret":               "******",
+		"12345678":             "********",
+		"4111111111111111":     "****1111",
+		"":                     "",
+	}
+	for in, want := range tests {
+		if got := mask(in); got != want {
+			t.Errorf("mask(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func newPolicy(t *testing.T, rules ...config.RedactionRule) *Policy {
+	t.Helper()
+	p, err := New(config.RedactionConfig{HashKey: "test", Rules: rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRowsActions(t *testing.T) {
+	p := newPolicy(t,
+		config.RedactionRule{Columns: []string{"password"}, Action: "drop"},
+		config.RedactionRule{Columns: []string{"phone"}, Action: "mask"},
+		config.RedactionRule{Columns: []string{"email"}, Action: "hash"},
+		config.RedactionRule{Values: []string{"jwt"}, Action: "drop"},
+		config.RedactionRule{Values: []string{"credit_card"}, Action: "mask"},
+	)
+
+	rows := []map[string]any{{
+		"id":       int64(1),
+		"password": "hunter2",
+		"phone":    []byte("+1 555 0100 2233"),
+		"email":    "alice@example.com",
+		"token":    "Bearer eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJhIn0.c2ln",
+		"note":     []byte("paid with 4111 1111 1111 1111 on 2024-01-02"),
+		"order":    "1234567890123456",
+		"missing":  nil,
+	}}
+	p.Rows("", "users", rows)
+	row := rows[0]
+
+	if _, ok := row["password"]; ok {
+		t.Error("password was not dropped by its column rule")
+	}
+	if _, ok := row["token"]; ok {
+		t.Error("token was not dropped by the jwt value rule")
+	}
+	if got := row["phone"]; got != "****2233" {
+		t.Errorf("phone = %#v, want the []byte value masked as a string", got)
+	}
+	if got, _ := row["email"].(string); !strings.HasPrefix(got, "hash:") {
+		t.Errorf("email = %q, want a hash", got)
+	}
+	if got := row["note"]; got != "paid with ****1111 on 2024-01-02" {
+		t.Errorf("note = %#v, want only the card number masked", got)
+	}
+	if got := row["order"]; got != "1234567890123456" {
+		t.Errorf("order = %#v, a digit run failing the Luhn check should be kept", got)
+	}
+	if got := row["id"]; got != int64(1) {
+		t.Errorf("id = %#v, want it unchanged", got)
+	}
+	if got, ok := row["missing"]; !ok || got != nil {
+		t.Errorf("missing = %#v, want nil kept", got)
+	}
+}
+
+func TestHash(t *testing.T) {
+	p := newPolicy(t, config.RedactionRule{Columns: []string{"email"}, Action: "hash"})
+	other := newPolicy(t, config.RedactionRule{Columns: []string{"email"}, Action: "hash"})
+	other.hashKey = []byte("other")
+
+	rows := []map[string]any{{"email": "alice@example.com"}, {"email": []byte("alice@example.com")}, {"email": "bob@example.com"}}
+	p.Rows("", "", rows)
+	if rows[0]["email"] != rows[1]["email"] {
+		t.Errorf("equal string and []byte values hashed to %v and %v", rows[0]["email"], rows[1]["email"])
+	}
+	if rows[0]["email"] == rows[2]["email"] {
+		t.Error("different values hashed to the same token")
+	}
+
+	keyed := []map[string]any{{"email": "alice@example.com"}}
+	other.Rows("", "", keyed)
+	if keyed[0]["email"] == rows[0]["email"] {
+		t.Error("the hash does not depend on the key")
+	}
+}
+
+func TestQueryRowsSources(t *testing.T) {
+	p := newPolicy(t,
+		config.RedactionRule{Columns: []string{"sales.customers.email"}, Action: "drop"},
+		config.RedactionRule{Columns: []string{"users.ssn"}, Action: "drop"},
+		config.RedactionRule{Columns: []string{"*.card_*"}, Action: "drop"},
+		config.RedactionRule{Columns: []string{"secret"}, Action: "drop"},
+	)
+
+	tests := []struct {
+		name    string
+		sources []Source
+		// kept are the columns of the row left after redaction
+		kept []string
+	}{
+		{"schema, table and column match", []Source{{Schema: "sales", Table: "customers"}}, []string{"id", "ssn"}},
+		{"other schema", []Source{{Schema: "public", Table: "customers"}}, []string{"email", "id", "ssn"}},
+		{"other table", []Source{{Schema: "sales", Table: "orders"}}, []string{"email", "id", "ssn"}},
+		{"table pattern in any schema", []Source{{Schema: "hr", Table: "users"}}, []string{"email", "id"}},
+		{"case-insensitive", []Source{{Schema: "SALES", Table: "Customers"}}, []string{"id", "ssn"}},
+		{"one of several sources", []Source{{Schema: "sales", Table: "orders"}, {Schema: "public", Table: "users"}}, []string{"email", "id"}},
+		{"unknown schema", []Source{{Table: "customers"}}, []string{"id", "ssn"}},
+		{"unknown source", []Source{{}}, []string{"id"}},
+		{"no source", nil, []string{"card_number", "email", "id", "ssn"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := []map[string]any{{"id": 1, "email": "a@b.io", "ssn": "078-05-1120", "card_number": "x", "secret": "y"}}
+			p.QueryRows(tt.sources, rows)
+
+			var kept []string
+			for _, column := range []string{"card_number", "email", "id", "secret", "ssn"} {
+				if _, ok := rows[0][column]; ok {
+					kept = append(kept, column)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.kept) {
+				t.Errorf("kept %q, want %q", kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestRowsNilPolicy(t *testing.T) {
+	var p *Policy
+	rows := []map[string]any{{"email": "alice@example.com"}}
+	p.Rows("public", "users", rows)
+	p.QueryRows(nil, rows)
+	if rows[0]["email"] != "alice@example.com" {
+		t.Errorf("nil policy changed the row to %v", rows[0])
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := map[string]config.RedactionRule{
+		"unknown action":       {Columns: []string{"email"}, Action: "encrypt"},
+		"no columns or values": {Action: "hash"},
+		"empty pattern part":   {Columns: []string{"users..email"}, Action: "hash"},
+		"too many parts":       {Columns: []string{"db.sales.users.email"}, Action: "hash"},
+		"invalid regexp":       {Values: []string{"("}, Action: "mask"},
+	}
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(config.RedactionConfig{Rules: []config.RedactionRule{rule}}); err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -1,22 +1,134 @@
 package types
 
+import "time"
+
+// Dialect identifies the SQL flavour spoken by a connector.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+	DialectSQLite   Dialect = "sqlite"
+)
+
 type Column struct {
 	Name     string `json:"name"`
 	Type     string `json:"type"`
 	Nullable bool   `json:"nullable"`
 }
 
+// Object kinds reported by Table.Kind and SchemaObject.Kind.
+const (
+	KindTable            = "table"
+	KindView             = "view"
+	KindMaterializedView = "materialized view"
+	KindFunction         = "function"
+	KindProcedure        = "procedure"
+	KindTrigger          = "trigger"
+)
+
 type Table struct {
 	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
 	Columns []Column `json:"columns"`
 }
 
+// SchemaObject is a view, materialized view, function, procedure or
+// trigger. Definition is the source of the object (CREATE statement, view
+// query or routine body) and is only filled in by DescribeObject.
+type SchemaObject struct {
+	Schema string `db:"object_schema" json:"schema"`
+	Name   string `db:"object_name" json:"name"`
+	Kind   string `db:"object_kind" json:"kind"`
+	// Table is the table a trigger fires on
+	Table string `db:"table_name" json:"table,omitempty"`
+	// Signature holds a routine's arguments and result type, e.g.
+	// "(customer integer) RETURNS numeric"
+	Signature  string `db:"signature" json:"signature,omitempty"`
+	Language   string `db:"language" json:"language,omitempty"`
+	Definition string `db:"definition" json:"definition,omitempty"`
+}
+
+// ScanResult is returned by Scan. NotFound lists requested tables that
+// matched nothing, with the closest existing name in Suggestions.
+type ScanResult struct {
+	Tables      []Table           `json:"tables"`
+	NotFound    []string          `json:"not_found,omitempty"`
+	Suggestions map[string]string `json:"suggestions,omitempty"`
+}
+
+// Schema summarises a PostgreSQL schema, MySQL database or SQLite attached
+// database.
+type Schema struct {
+	Name    string `json:"name"`
+	Tables  int    `json:"tables"`
+	Default bool   `json:"default,omitempty"`
+}
+
 type Index struct {
 	Name    string   `json:"name"`
 	Columns []string `json:"columns"`
 	Unique  bool     `json:"unique"`
 }
 
+// Relationship links columns of one table to the columns they reference in
+// another. Inferred relationships are guessed from column names (a
+// customer_id column pointing at customers.id) rather than declared as
+// foreign keys. Table names are schema-qualified.
+type Relationship struct {
+	Name              string   `json:"name,omitempty"`
+	Table             string   `json:"table"`
+	Columns           []string `json:"columns"`
+	ReferencedTable   string   `json:"referenced_table"`
+	ReferencedColumns []string `json:"referenced_columns"`
+	OnUpdate          string   `json:"on_update,omitempty"`
+	OnDelete          string   `json:"on_delete,omitempty"`
+	Inferred          bool     `json:"inferred,omitempty"`
+}
+
+// JoinPathOptions shapes the paths returned by JoinPaths.
+type JoinPathOptions struct {
+	// IncludeInferred also follows relationships guessed from column names
+	IncludeInferred bool
+	MaxPaths        int
+}
+
+// JoinPath is one way to join a set of tables: a FROM clause with a JOIN
+// per relationship followed. OneToManyJoins counts the joins onto the
+// referencing side of a key, which can repeat rows.
+type JoinPath struct {
+	SQL            string         `json:"sql"`
+	Tables         []string       `json:"tables"`
+	Joins          int            `json:"joins"`
+	OneToManyJoins int            `json:"one_to_many_joins"`
+	InferredJoins  int            `json:"inferred_joins"`
+	Relationships  []Relationship `json:"relationships"`
+}
+
+// DiagramOptions selects the tables of a SchemaGraph: the named Tables
+// together with the tables within Hops relationships of Focus. With
+// neither set every table is included.
+type DiagramOptions struct {
+	Tables          []string
+	Focus           string
+	Hops            int
+	IncludeInferred bool
+}
+
+// SchemaGraph holds what an ER diagram shows. Tables are named without
+// their schema unless two of them share a name.
+type SchemaGraph struct {
+	Tables        []GraphTable   `json:"tables"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// GraphTable is one table of a SchemaGraph.
+type GraphTable struct {
+	Name        string   `json:"name"`
+	Columns     []Column `json:"columns"`
+	PrimaryKeys []string `json:"primary_keys,omitempty"`
+}
+
 type TableDescription struct {
 	Name        string           `json:"name"`
 	Columns     []Column         `json:"columns"`
@@ -24,4 +136,105 @@ type TableDescription struct {
 	SampleData  []map[string]any `json:"sample_data,omitempty"`
 	Indexes     []Index          `json:"indexes,omitempty"`
 	PrimaryKeys []string         `json:"primary_keys,omitempty"`
+	// ForeignKeys are the relationships from this table, ReferencedBy the
+	// ones from other tables pointing at it
+	ForeignKeys  []Relationship `json:"foreign_keys,omitempty"`
+	ReferencedBy []Relationship `json:"referenced_by,omitempty"`
+}
+
+// DescribeOptions controls which of the more expensive parts of a
+// TableDescription are collected.
+type DescribeOptions struct {
+	SkipRowCount   bool
+	SkipSampleData bool
+	// SampleMaxBytes and SampleTimeout bound the sample rows query as in
+	// QueryOptions
+	SampleMaxBytes int
+	SampleTimeout  time.Duration
+	// CountTimeout bounds the row count query
+	CountTimeout time.Duration
+}
+
+// SampleOptions shapes the rows returned by Sample. Columns and OrderBy
+// are validated against the table's columns; Where must pass the read-only
+// guard before it is added to the query.
+type SampleOptions struct {
+	Limit   int
+	Columns []string
+	OrderBy string
+	Where   string
+	Random  bool
+	// MaxBytes and Timeout bound the sample query as in QueryOptions
+	MaxBytes int
+	Timeout  time.Duration
+	// OnQuery, if set, receives the statement before it runs
+	OnQuery func(query string)
+}
+
+// QueryOptions bounds how much of a result Query reads. Zero values mean
+// no limit.
+type QueryOptions struct {
+	MaxRows int
+	// MaxBytes caps the JSON encoded size of the rows read
+	MaxBytes int
+	// Timeout is passed to the database as a statement timeout, where the
+	// dialect has one, as well as bounding the call's context
+	Timeout time.Duration
+}
+
+// QueryResult holds the rows read by Query and whether reading stopped at
+// one of the QueryOptions limits.
+type QueryResult struct {
+	Rows      []map[string]any `json:"rows"`
+	RowCount  int              `json:"row_count"`
+	Truncated bool             `json:"truncated"`
+	// Limit names the limit that stopped reading, max_rows or
+	// max_result_bytes
+	Limit string `json:"limit,omitempty"`
+}
+
+// QueryPlan is a planner's plan for a query, normalized across dialects.
+type QueryPlan struct {
+	Dialect Dialect `json:"dialect"`
+	// TotalCost is the planner's cost estimate for the whole query, in the
+	// database's own units; nil where the planner gives none (SQLite)
+	TotalCost *float64 `json:"total_cost,omitempty"`
+	// EstimatedRows is the number of rows the query is expected to
+	// produce; for MySQL the largest join result. Nil for SQLite
+	EstimatedRows *float64   `json:"estimated_rows,omitempty"`
+	Nodes         []PlanNode `json:"nodes"`
+}
+
+// PlanNode is one step of a QueryPlan.
+type PlanNode struct {
+	// NodeType names the operation, e.g. Seq Scan, Index Scan, Hash Join
+	NodeType string `json:"node_type"`
+	// Relation is the table the node reads, if any
+	Relation string `json:"relation,omitempty"`
+	// Index is the index the node uses, if any
+	Index         string   `json:"index,omitempty"`
+	EstimatedRows *float64 `json:"estimated_rows,omitempty"`
+	// Cost is the estimated cost up to and including this node
+	Cost *float64 `json:"cost,omitempty"`
+	// Detail holds the planner's own description where it adds something,
+	// e.g. a filter or SQLite's plan line
+	Detail   string     `json:"detail,omitempty"`
+	Children []PlanNode `json:"children,omitempty"`
+}
+
+// DatabaseStatus describes one named connection for the list_databases tool.
+type DatabaseStatus struct {
+	Name      string  `json:"name"`
+	Dialect   Dialect `json:"dialect"`
+	Default   bool    `json:"default"`
+	Reachable bool    `json:"reachable"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// RefreshResult reports which cached catalog entries refresh_schema dropped.
+type RefreshResult struct {
+	Database string `json:"database"`
+	// All is set when the whole cache was dropped
+	All    bool     `json:"all"`
+	Tables []string `json:"tables,omitempty"`
 }